@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/andybalholm/brotli"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// arbitrumBrotliMessageHeaderByte prefixes brotli compressed sequencer batches
+	arbitrumBrotliMessageHeaderByte = 0x00
+	// arbitrumMaxDecompressedLen bounds the size of a decompressed sequencer batch
+	arbitrumMaxDecompressedLen = 16 << 20
+
+	arbitrumSegmentKindL2Message            = 0
+	arbitrumSegmentKindL2MessageBrotli      = 1
+	arbitrumSegmentKindDelayedMessages      = 2
+	arbitrumSegmentKindAdvanceTimestamp     = 3
+	arbitrumSegmentKindAdvanceL1BlockNumber = 4
+)
+
+var arbitrumL2MessageKinds = map[byte]string{
+	0: "unsigned_user_tx",
+	1: "contract_tx",
+	2: "nonmutating_call",
+	3: "batch",
+	4: "signed_tx",
+	6: "heartbeat",
+	7: "signed_compressed_tx",
+}
+
+type arbitrumSegment struct {
+	Kind      string        `json:"kind"`
+	L2Kind    string        `json:"l2_kind,omitempty"`
+	Data      hexutil.Bytes `json:"data,omitempty"`
+	Advance   uint64        `json:"advance,omitempty"`
+	DecodeErr string        `json:"error,omitempty"`
+}
+
+// arbitrumBatch is an Arbitrum Nitro sequencer batch
+type arbitrumBatch struct {
+	Segments []arbitrumSegment `json:"segments"`
+}
+
+// arbitrumDecoder prints the segments of Arbitrum Nitro sequencer batches. A batch is RLP encoded across every blob
+// of the batch poster transaction.
+type arbitrumDecoder struct {
+	out   *json.Encoder
+	buf   []byte
+	blobs int
+}
+
+func newArbitrumDecoder(out io.Writer) *arbitrumDecoder {
+	return &arbitrumDecoder{out: json.NewEncoder(out)}
+}
+
+func (d *arbitrumDecoder) Codec() BlobCodec {
+	return paddedCodec{}
+}
+
+func (d *arbitrumDecoder) AddBlob(data []byte) error {
+	d.buf = append(d.buf, data...)
+	d.blobs++
+	_, payload, _, err := rlp.Split(d.buf)
+	if errors.Is(err, rlp.ErrValueTooLarge) && d.blobs < maxBlobsPerBlock {
+		// the batch continues in the next blob
+		return nil
+	}
+	d.buf, d.blobs = nil, 0
+	if err != nil {
+		return fmt.Errorf("%w: invalid batch encoding", err)
+	}
+	batch, err := decodeArbitrumBatch(payload)
+	if err != nil {
+		return err
+	}
+	return d.out.Encode(batch)
+}
+
+func (d *arbitrumDecoder) Flush() error {
+	if d.blobs != 0 {
+		log.Printf("incomplete arbitrum batch. blobs=%d", d.blobs)
+	}
+	return nil
+}
+
+// decodeArbitrumBatch decompresses a sequencer batch and splits it into its segments
+func decodeArbitrumBatch(payload []byte) (*arbitrumBatch, error) {
+	if len(payload) == 0 || payload[0] != arbitrumBrotliMessageHeaderByte {
+		return nil, errors.New("batch is not brotli compressed")
+	}
+	decompressed, err := io.ReadAll(io.LimitReader(brotli.NewReader(bytes.NewReader(payload[1:])), arbitrumMaxDecompressedLen))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to decompress batch", err)
+	}
+
+	batch := new(arbitrumBatch)
+	s := rlp.NewStream(bytes.NewReader(decompressed), 0)
+	for {
+		segment, err := s.Bytes()
+		if errors.Is(err, io.EOF) {
+			return batch, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: invalid segment %d", err, len(batch.Segments))
+		}
+		if len(segment) == 0 {
+			return nil, fmt.Errorf("empty segment %d", len(batch.Segments))
+		}
+		batch.Segments = append(batch.Segments, decodeArbitrumSegment(segment))
+	}
+}
+
+func decodeArbitrumSegment(segment []byte) arbitrumSegment {
+	kind, data := segment[0], segment[1:]
+	var s arbitrumSegment
+	switch kind {
+	case arbitrumSegmentKindL2Message, arbitrumSegmentKindL2MessageBrotli:
+		s.Kind = "l2_message"
+		if kind == arbitrumSegmentKindL2MessageBrotli {
+			var err error
+			if data, err = io.ReadAll(io.LimitReader(brotli.NewReader(bytes.NewReader(data)), arbitrumMaxDecompressedLen)); err != nil {
+				s.DecodeErr = err.Error()
+				return s
+			}
+		}
+		if len(data) != 0 {
+			s.L2Kind = arbitrumL2MessageKinds[data[0]]
+			if s.L2Kind == "" {
+				s.L2Kind = fmt.Sprintf("unknown_%d", data[0])
+			}
+			data = data[1:]
+		}
+		s.Data = data
+	case arbitrumSegmentKindDelayedMessages:
+		s.Kind = "delayed_messages"
+	case arbitrumSegmentKindAdvanceTimestamp, arbitrumSegmentKindAdvanceL1BlockNumber:
+		s.Kind = "advance_timestamp"
+		if kind == arbitrumSegmentKindAdvanceL1BlockNumber {
+			s.Kind = "advance_l1_block_number"
+		}
+		if err := rlp.DecodeBytes(data, &s.Advance); err != nil {
+			s.DecodeErr = err.Error()
+		}
+	default:
+		s.Kind = fmt.Sprintf("unknown_%d", kind)
+		s.Data = data
+	}
+	return s
+}
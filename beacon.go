@@ -0,0 +1,401 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// errBeaconNotFound is returned when the beacon node has no data for the requested block, e.g. a missed slot
+var errBeaconNotFound = errors.New("not found")
+
+type beaconClient struct {
+	url    string
+	client *http.Client
+}
+
+func newBeaconClient(url string) *beaconClient {
+	return &beaconClient{
+		url:    strings.TrimRight(url, "/"),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// BeaconBlockHeader is the header of the block a sidecar belongs to
+type BeaconBlockHeader struct {
+	Slot          uint64      `json:"slot,string"`
+	ProposerIndex uint64      `json:"proposer_index,string"`
+	ParentRoot    common.Hash `json:"parent_root"`
+	StateRoot     common.Hash `json:"state_root"`
+	BodyRoot      common.Hash `json:"body_root"`
+}
+
+type SignedBeaconBlockHeader struct {
+	Message   BeaconBlockHeader `json:"message"`
+	Signature hexutil.Bytes     `json:"signature"`
+}
+
+// BlobSidecar is a deneb blob sidecar as served by the beacon node API
+type BlobSidecar struct {
+	Index             uint64
+	Blob              kzg4844.Blob
+	KZGCommitment     kzg4844.Commitment
+	KZGProof          kzg4844.Proof
+	SignedBlockHeader SignedBeaconBlockHeader
+
+	KZGCommitmentInclusionProof []common.Hash
+}
+
+func (s *BlobSidecar) UnmarshalJSON(input []byte) error {
+	var dec struct {
+		Index             uint64                  `json:"index,string"`
+		Blob              hexutil.Bytes           `json:"blob"`
+		KZGCommitment     hexutil.Bytes           `json:"kzg_commitment"`
+		KZGProof          hexutil.Bytes           `json:"kzg_proof"`
+		SignedBlockHeader SignedBeaconBlockHeader `json:"signed_block_header"`
+
+		KZGCommitmentInclusionProof []common.Hash `json:"kzg_commitment_inclusion_proof"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if len(dec.Blob) != len(s.Blob) {
+		return fmt.Errorf("invalid blob length %d", len(dec.Blob))
+	}
+	if len(dec.KZGCommitment) != len(s.KZGCommitment) {
+		return fmt.Errorf("invalid kzg_commitment length %d", len(dec.KZGCommitment))
+	}
+	if len(dec.KZGProof) != len(s.KZGProof) {
+		return fmt.Errorf("invalid kzg_proof length %d", len(dec.KZGProof))
+	}
+	if len(dec.KZGCommitmentInclusionProof) != kzgCommitmentInclusionProofDepth {
+		return fmt.Errorf("invalid kzg_commitment_inclusion_proof length %d", len(dec.KZGCommitmentInclusionProof))
+	}
+	s.Index = dec.Index
+	copy(s.Blob[:], dec.Blob)
+	copy(s.KZGCommitment[:], dec.KZGCommitment)
+	copy(s.KZGProof[:], dec.KZGProof)
+	s.SignedBlockHeader = dec.SignedBlockHeader
+	s.KZGCommitmentInclusionProof = dec.KZGCommitmentInclusionProof
+	return nil
+}
+
+// DataColumnSidecar is a fulu data column sidecar, holding the cells at one column index of all blobs of a block
+type DataColumnSidecar struct {
+	Index             uint64
+	Column            []Cell
+	KZGCommitments    []kzg4844.Commitment
+	KZGProofs         []kzg4844.Proof
+	SignedBlockHeader SignedBeaconBlockHeader
+
+	KZGCommitmentsInclusionProof []common.Hash
+}
+
+func (s *DataColumnSidecar) UnmarshalJSON(input []byte) error {
+	var dec struct {
+		Index             uint64                  `json:"index,string"`
+		Column            []hexutil.Bytes         `json:"column"`
+		KZGCommitments    []hexutil.Bytes         `json:"kzg_commitments"`
+		KZGProofs         []hexutil.Bytes         `json:"kzg_proofs"`
+		SignedBlockHeader SignedBeaconBlockHeader `json:"signed_block_header"`
+
+		KZGCommitmentsInclusionProof []common.Hash `json:"kzg_commitments_inclusion_proof"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if len(dec.KZGCommitments) != len(dec.Column) || len(dec.KZGProofs) != len(dec.Column) {
+		return fmt.Errorf("mismatched number of cells %d, kzg_commitments %d and kzg_proofs %d", len(dec.Column),
+			len(dec.KZGCommitments), len(dec.KZGProofs))
+	}
+	if len(dec.KZGCommitmentsInclusionProof) != kzgCommitmentsInclusionProofDepth {
+		return fmt.Errorf("invalid kzg_commitments_inclusion_proof length %d", len(dec.KZGCommitmentsInclusionProof))
+	}
+	s.Index = dec.Index
+	s.Column = make([]Cell, len(dec.Column))
+	s.KZGCommitments = make([]kzg4844.Commitment, len(dec.Column))
+	s.KZGProofs = make([]kzg4844.Proof, len(dec.Column))
+	for i := range dec.Column {
+		if len(dec.Column[i]) != len(s.Column[i]) {
+			return fmt.Errorf("invalid cell length %d", len(dec.Column[i]))
+		}
+		if len(dec.KZGCommitments[i]) != len(s.KZGCommitments[i]) {
+			return fmt.Errorf("invalid kzg_commitment length %d", len(dec.KZGCommitments[i]))
+		}
+		if len(dec.KZGProofs[i]) != len(s.KZGProofs[i]) {
+			return fmt.Errorf("invalid kzg_proof length %d", len(dec.KZGProofs[i]))
+		}
+		copy(s.Column[i][:], dec.Column[i])
+		copy(s.KZGCommitments[i][:], dec.KZGCommitments[i])
+		copy(s.KZGProofs[i][:], dec.KZGProofs[i])
+	}
+	s.SignedBlockHeader = dec.SignedBlockHeader
+	s.KZGCommitmentsInclusionProof = dec.KZGCommitmentsInclusionProof
+	return nil
+}
+
+// Slot returns the slot of the block the sidecar belongs to
+func (s *DataColumnSidecar) Slot() uint64 {
+	return s.SignedBlockHeader.Message.Slot
+}
+
+// Slot returns the slot of the block the sidecar belongs to
+func (s *BlobSidecar) Slot() uint64 {
+	return s.SignedBlockHeader.Message.Slot
+}
+
+// BlockRoot returns the root of the block the sidecar belongs to
+func (s *BlobSidecar) BlockRoot() common.Hash {
+	return s.SignedBlockHeader.Message.HashTreeRoot()
+}
+
+// BlobSidecars fetches the blob sidecars of a block. The block ID may be a slot, a block root, "head" or "finalized".
+// If indices are given, only the sidecars at those indices are fetched.
+func (c *beaconClient) BlobSidecars(ctx context.Context, blockID string, indices ...uint64) ([]*BlobSidecar, error) {
+	var resp struct {
+		Data []*BlobSidecar `json:"data"`
+	}
+	if err := c.get(ctx, "/eth/v1/beacon/blob_sidecars/"+blockID+indicesQuery(indices), &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// DataColumnSidecars fetches the data column sidecars of a block, or only those at the given column indices
+func (c *beaconClient) DataColumnSidecars(ctx context.Context, blockID string, indices ...uint64) ([]*DataColumnSidecar, error) {
+	var resp struct {
+		Data []*DataColumnSidecar `json:"data"`
+	}
+	if err := c.get(ctx, "/eth/v1/debug/beacon/data_column_sidecars/"+blockID+indicesQuery(indices), &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+func indicesQuery(indices []uint64) string {
+	if len(indices) == 0 {
+		return ""
+	}
+	query := make([]string, len(indices))
+	for i, index := range indices {
+		query[i] = strconv.FormatUint(index, 10)
+	}
+	return "?indices=" + strings.Join(query, ",")
+}
+
+// ExecutionBlockHash returns the hash of the execution payload of a block
+func (c *beaconClient) ExecutionBlockHash(ctx context.Context, blockID string) (common.Hash, error) {
+	var resp struct {
+		Data struct {
+			Message struct {
+				Body struct {
+					ExecutionPayload struct {
+						BlockHash common.Hash `json:"block_hash"`
+					} `json:"execution_payload"`
+				} `json:"body"`
+			} `json:"message"`
+		} `json:"data"`
+	}
+	if err := c.get(ctx, "/eth/v2/beacon/blocks/"+blockID, &resp); err != nil {
+		return common.Hash{}, err
+	}
+	return resp.Data.Message.Body.ExecutionPayload.BlockHash, nil
+}
+
+// BlockRoot returns the root of the block header the beacon node reports for blockID
+func (c *beaconClient) BlockRoot(ctx context.Context, blockID string) (common.Hash, error) {
+	var resp struct {
+		Data struct {
+			Header SignedBeaconBlockHeader `json:"header"`
+		} `json:"data"`
+	}
+	if err := c.get(ctx, "/eth/v1/beacon/headers/"+blockID, &resp); err != nil {
+		return common.Hash{}, err
+	}
+	return resp.Data.Header.Message.HashTreeRoot(), nil
+}
+
+// Events subscribes to the beacon node event stream and calls fn for every event received on the given topics until
+// the stream ends, ctx is cancelled, or fn returns an error.
+func (c *beaconClient) Events(ctx context.Context, topics []string, fn func(topic string, data []byte) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+"/eth/v1/events?topics="+strings.Join(topics, ","), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	// the stream is long-lived, so it can't use the client timeout
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("beacon node returned %d for event stream: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var (
+		topic string
+		data  []byte
+	)
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "":
+			if topic != "" && len(data) != 0 {
+				if err := fn(topic, data); err != nil {
+					return err
+				}
+			}
+			topic, data = "", nil
+		case strings.HasPrefix(line, "event:"):
+			topic = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimSpace(strings.TrimPrefix(line, "data:"))...)
+		}
+	}
+}
+
+// GenesisTime returns the unix timestamp of the beacon chain genesis
+func (c *beaconClient) GenesisTime(ctx context.Context) (uint64, error) {
+	var resp struct {
+		Data struct {
+			GenesisTime uint64 `json:"genesis_time,string"`
+		} `json:"data"`
+	}
+	if err := c.get(ctx, "/eth/v1/beacon/genesis", &resp); err != nil {
+		return 0, err
+	}
+	return resp.Data.GenesisTime, nil
+}
+
+// SecondsPerSlot returns the slot duration configured on the beacon node
+func (c *beaconClient) SecondsPerSlot(ctx context.Context) (uint64, error) {
+	var resp struct {
+		Data struct {
+			SecondsPerSlot uint64 `json:"SECONDS_PER_SLOT,string"`
+		} `json:"data"`
+	}
+	if err := c.get(ctx, "/eth/v1/config/spec", &resp); err != nil {
+		return 0, err
+	}
+	if resp.Data.SecondsPerSlot == 0 {
+		return 0, errors.New("beacon node spec is missing SECONDS_PER_SLOT")
+	}
+	return resp.Data.SecondsPerSlot, nil
+}
+
+// ForkSchedule returns the fork schedule of the chain of the beacon node
+func (c *beaconClient) ForkSchedule(ctx context.Context) (*forkSchedule, error) {
+	var forks struct {
+		Data []struct {
+			CurrentVersion hexutil.Bytes `json:"current_version"`
+			Epoch          uint64        `json:"epoch,string"`
+		} `json:"data"`
+	}
+	if err := c.get(ctx, "/eth/v1/config/fork_schedule", &forks); err != nil {
+		return nil, err
+	}
+	var genesis struct {
+		Data struct {
+			GenesisValidatorsRoot common.Hash `json:"genesis_validators_root"`
+		} `json:"data"`
+	}
+	if err := c.get(ctx, "/eth/v1/beacon/genesis", &genesis); err != nil {
+		return nil, err
+	}
+	var spec struct {
+		Data struct {
+			SlotsPerEpoch uint64 `json:"SLOTS_PER_EPOCH,string"`
+		} `json:"data"`
+	}
+	if err := c.get(ctx, "/eth/v1/config/spec", &spec); err != nil {
+		return nil, err
+	}
+	if spec.Data.SlotsPerEpoch == 0 {
+		return nil, errors.New("beacon node spec is missing SLOTS_PER_EPOCH")
+	}
+
+	schedule := &forkSchedule{
+		genesisValidatorsRoot: genesis.Data.GenesisValidatorsRoot,
+		slotsPerEpoch:         spec.Data.SlotsPerEpoch,
+	}
+	for _, fork := range forks.Data {
+		if len(fork.CurrentVersion) != 4 {
+			return nil, fmt.Errorf("invalid fork version %v", fork.CurrentVersion)
+		}
+		var version forkVersion
+		version.Epoch = fork.Epoch
+		copy(version.Version[:], fork.CurrentVersion)
+		schedule.forks = append(schedule.forks, version)
+	}
+	if len(schedule.forks) == 0 {
+		return nil, errors.New("beacon node returned an empty fork schedule")
+	}
+	sort.Slice(schedule.forks, func(i, j int) bool { return schedule.forks[i].Epoch < schedule.forks[j].Epoch })
+	return schedule, nil
+}
+
+// SlotAt returns the slot that started at the given unix timestamp
+func (c *beaconClient) SlotAt(ctx context.Context, timestamp uint64) (uint64, error) {
+	genesisTime, err := c.GenesisTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+	secondsPerSlot, err := c.SecondsPerSlot(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if timestamp < genesisTime {
+		return 0, fmt.Errorf("timestamp %d is before genesis %d", timestamp, genesisTime)
+	}
+	return (timestamp - genesisTime) / secondsPerSlot, nil
+}
+
+func (c *beaconClient) get(ctx context.Context, path string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(body, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(body))
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%w: %s: %s", errBeaconNotFound, path, apiErr.Message)
+		}
+		return fmt.Errorf("beacon node returned %d for %s: %s", resp.StatusCode, path, apiErr.Message)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("%w: unable to decode response of %s", err, path)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// blobSidecarJSON returns the beacon node API encoding of a sidecar
+func blobSidecarJSON(s *BlobSidecar) map[string]interface{} {
+	return map[string]interface{}{
+		"index":                          strconv.FormatUint(s.Index, 10),
+		"blob":                           hexutil.Bytes(s.Blob[:]),
+		"kzg_commitment":                 hexutil.Bytes(s.KZGCommitment[:]),
+		"kzg_proof":                      hexutil.Bytes(s.KZGProof[:]),
+		"signed_block_header":            s.SignedBlockHeader,
+		"kzg_commitment_inclusion_proof": s.KZGCommitmentInclusionProof,
+	}
+}
+
+// blobSidecarsResponse returns the beacon node API response holding the sidecars
+func blobSidecarsResponse(sidecars ...*BlobSidecar) interface{} {
+	encoded := []interface{}{}
+	for _, sidecar := range sidecars {
+		encoded = append(encoded, blobSidecarJSON(sidecar))
+	}
+	return map[string]interface{}{"data": encoded}
+}
+
+// newTestBeaconServer serves the JSON encoding of the responses by request URI, and a 404 for any other request
+func newTestBeaconServer(t *testing.T, responses map[string]interface{}) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, ok := responses[r.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"NOT_FOUND: beacon block"}`))
+			return
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestBeaconBlobSidecars(t *testing.T) {
+	sidecars := testBlobSidecars(t, 5)
+	truncated := blobSidecarJSON(sidecars[0])
+	truncated["blob"] = hexutil.Bytes(sidecars[0].Blob[:100])
+	srv := newTestBeaconServer(t, map[string]interface{}{
+		"/eth/v1/beacon/blob_sidecars/5":           blobSidecarsResponse(sidecars...),
+		"/eth/v1/beacon/blob_sidecars/5?indices=1": blobSidecarsResponse(sidecars[1]),
+		"/eth/v1/beacon/blob_sidecars/6":           map[string]interface{}{"data": []interface{}{truncated}},
+		"/eth/v1/beacon/blob_sidecars/8":           blobSidecarsResponse(),
+	})
+	client := newBeaconClient(srv.URL + "/")
+	ctx := context.Background()
+
+	decoded, err := client.BlobSidecars(ctx, "5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, sidecars) {
+		t.Fatal("unexpected decoded sidecars")
+	}
+	if err := verifyBlobSidecars("5", decoded); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err = client.BlobSidecars(ctx, "5", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || !reflect.DeepEqual(decoded[0], sidecars[1]) {
+		t.Fatal("unexpected decoded sidecar")
+	}
+
+	if _, err := client.BlobSidecars(ctx, "6"); err == nil {
+		t.Fatal("expected truncated blob to be rejected")
+	}
+	if _, err := client.BlobSidecars(ctx, "7"); !errors.Is(err, errBeaconNotFound) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if _, err := downloadBlobSidecars(ctx, client, "8"); err == nil {
+		t.Fatal("expected block without blobs to be rejected")
+	}
+	if decoded, err = downloadBlobSidecars(ctx, client, "5"); err != nil || len(decoded) != len(sidecars) {
+		t.Fatalf("unexpected download of %d sidecars: %v", len(decoded), err)
+	}
+}
+
+func TestDownloadBlobSidecarsOtherBlock(t *testing.T) {
+	sidecars := testBlobSidecars(t, 5)
+	other := sidecars[0].SignedBlockHeader
+	other.Message.Slot = 6
+	headerResponse := func(header SignedBeaconBlockHeader) interface{} {
+		return map[string]interface{}{"data": map[string]interface{}{"header": header}}
+	}
+	srv := newTestBeaconServer(t, map[string]interface{}{
+		"/eth/v1/beacon/headers/head":                                        headerResponse(sidecars[0].SignedBlockHeader),
+		"/eth/v1/beacon/headers/finalized":                                   headerResponse(other),
+		"/eth/v1/beacon/blob_sidecars/" + sidecars[0].BlockRoot().Hex():      blobSidecarsResponse(sidecars...),
+		"/eth/v1/beacon/blob_sidecars/" + other.Message.HashTreeRoot().Hex(): blobSidecarsResponse(sidecars...),
+		"/eth/v1/beacon/blob_sidecars/6":                                     blobSidecarsResponse(sidecars...),
+	})
+	client := newBeaconClient(srv.URL)
+	ctx := context.Background()
+
+	if decoded, err := downloadBlobSidecars(ctx, client, "head"); err != nil || len(decoded) != len(sidecars) {
+		t.Fatalf("unexpected download of %d sidecars: %v", len(decoded), err)
+	}
+	// the node serves the sidecars of slot 5 for the finalized block and slot 6
+	for _, blockID := range []string{"finalized", "6"} {
+		if _, err := downloadBlobSidecars(ctx, client, blockID); err == nil {
+			t.Fatalf("%s: expected the sidecars of another block to be rejected", blockID)
+		}
+	}
+	if _, err := downloadBlobSidecars(ctx, client, "justified"); !errors.Is(err, errBeaconNotFound) {
+		t.Fatalf("expected unknown block, got %v", err)
+	}
+}
+
+func TestSlotAt(t *testing.T) {
+	srv := newTestBeaconServer(t, map[string]interface{}{
+		"/eth/v1/beacon/genesis": map[string]interface{}{"data": map[string]string{"genesis_time": "1000"}},
+		"/eth/v1/config/spec":    map[string]interface{}{"data": map[string]string{"SECONDS_PER_SLOT": "12"}},
+	})
+	client := newBeaconClient(srv.URL)
+	for _, tt := range []struct {
+		timestamp, slot uint64
+	}{{1000, 0}, {1011, 0}, {1012, 1}, {1000 + 12*100, 100}} {
+		slot, err := client.SlotAt(context.Background(), tt.timestamp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if slot != tt.slot {
+			t.Fatalf("expected slot %d at %d, got %d", tt.slot, tt.timestamp, slot)
+		}
+	}
+	if _, err := client.SlotAt(context.Background(), 999); err == nil {
+		t.Fatal("expected timestamp before genesis to be rejected")
+	}
+}
+
+func TestBlobSidecarUnmarshalJSON(t *testing.T) {
+	sidecar := testBlobSidecars(t, 1)[0]
+	tests := []struct {
+		field string
+		value interface{}
+	}{
+		{"index", "x"},
+		{"blob", hexutil.Bytes(sidecar.Blob[1:])},
+		{"kzg_commitment", hexutil.Bytes(sidecar.KZGCommitment[1:])},
+		{"kzg_proof", hexutil.Bytes(append(sidecar.KZGProof[:], 0))},
+		{"kzg_commitment_inclusion_proof", sidecar.KZGCommitmentInclusionProof[1:]},
+	}
+	for _, tt := range tests {
+		encoded := blobSidecarJSON(sidecar)
+		encoded[tt.field] = tt.value
+		input, err := json.Marshal(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(input, new(BlobSidecar)); err == nil {
+			t.Fatalf("expected invalid %s to be rejected", tt.field)
+		}
+	}
+}
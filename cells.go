@@ -0,0 +1,596 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"math/bits"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/urfave/cli"
+)
+
+const (
+	// fieldElementsPerExtBlob is FIELD_ELEMENTS_PER_EXT_BLOB from EIP-7594, the number of evaluations of a blob
+	// extended with Reed-Solomon
+	fieldElementsPerExtBlob = 2 * params.BlobTxFieldElementsPerBlob
+	// fieldElementsPerCell is FIELD_ELEMENTS_PER_CELL from EIP-7594
+	fieldElementsPerCell = 64
+	// cellsPerExtBlob is CELLS_PER_EXT_BLOB from EIP-7594
+	cellsPerExtBlob = fieldElementsPerExtBlob / fieldElementsPerCell
+	bytesPerCell    = fieldElementsPerCell * 32
+)
+
+// Cell is a contiguous range of the evaluations of an extended blob, in bit-reversed order. Its evaluations are over a
+// coset of the 64th roots of unity.
+type Cell [bytesPerCell]byte
+
+// blobCells are the cells of a blob with their proofs, as printed by the cells command and read back by it
+type blobCells struct {
+	BlobIndex     int           `json:"blob_index"`
+	VersionedHash common.Hash   `json:"versioned_hash"`
+	Commitment    hexutil.Bytes `json:"commitment"`
+	Cells         []cellProof   `json:"cells"`
+}
+
+type cellProof struct {
+	Index uint64        `json:"index"`
+	Cell  hexutil.Bytes `json:"cell"`
+	Proof hexutil.Bytes `json:"proof"`
+}
+
+func CellsApp(cliCtx *cli.Context) error {
+	var indices []uint64
+	if cliCtx.IsSet(CellsCellIndicesFlag.Name) {
+		var err error
+		if indices, err = parseCellIndices(cliCtx.String(CellsCellIndicesFlag.Name)); err != nil {
+			return err
+		}
+	}
+
+	var out []blobCells
+	switch {
+	case cliCtx.IsSet(CellsFileFlag.Name):
+		in, err := readBlobCells(cliCtx.String(CellsFileFlag.Name))
+		if err != nil {
+			return err
+		}
+		for _, b := range in {
+			commitment, cellIndices, cells, proofs, err := b.unpack()
+			if err != nil {
+				return fmt.Errorf("%w: blob %d", err, b.BlobIndex)
+			}
+			commitments := make([]kzg4844.Commitment, len(cells))
+			for i := range commitments {
+				commitments[i] = commitment
+			}
+			if err := VerifyCellKZGProofBatch(commitments, cellIndices, cells, proofs); err != nil {
+				return fmt.Errorf("%w: blob %d", err, b.BlobIndex)
+			}
+			log.Printf("cells verified. blob=%d cells=%d", b.BlobIndex, len(cells))
+			if !cliCtx.Bool(CellsRecoverFlag.Name) {
+				continue
+			}
+			cells, proofs, err = RecoverCellsAndKZGProofs(cellIndices, cells)
+			if err != nil {
+				return fmt.Errorf("%w: unable to recover blob %d", err, b.BlobIndex)
+			}
+			out = append(out, newBlobCells(b.BlobIndex, commitment, cells, proofs, indices))
+		}
+		if !cliCtx.Bool(CellsRecoverFlag.Name) {
+			return nil
+		}
+	case cliCtx.IsSet(CellsBlobFileFlag.Name):
+		if cliCtx.Bool(CellsRecoverFlag.Name) {
+			return fmt.Errorf("--%s requires --%s", CellsRecoverFlag.Name, CellsFileFlag.Name)
+		}
+		data, err := os.ReadFile(cliCtx.String(CellsBlobFileFlag.Name))
+		if err != nil {
+			return fmt.Errorf("error reading blob file: %v", err)
+		}
+		codec, err := lookupBlobCodec(cliCtx.String(CellsCodecFlag.Name))
+		if err != nil {
+			return err
+		}
+		var blobs []kzg4844.Blob
+		if cliCtx.Bool(CellsRawBlobFlag.Name) {
+			blobs, err = splitRawBlobs(data)
+		} else {
+			blobs, err = codec.Encode(data)
+		}
+		if err != nil {
+			return err
+		}
+		commitments, cells, proofs, err := ComputeBlobCells(blobs)
+		if err != nil {
+			return err
+		}
+		for i := range blobs {
+			out = append(out, newBlobCells(i, commitments[i], cells[i], proofs[i], indices))
+		}
+	default:
+		return fmt.Errorf("either --%s or --%s is required", CellsBlobFileFlag.Name, CellsFileFlag.Name)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// newBlobCells collects the cells with the given indices, or all cells if there are none
+func newBlobCells(blobIndex int, commitment kzg4844.Commitment, cells []Cell, proofs []kzg4844.Proof, indices []uint64) blobCells {
+	b := blobCells{BlobIndex: blobIndex, VersionedHash: kZGToVersionedHash(commitment), Commitment: commitment[:]}
+	if indices == nil {
+		for i := range cells {
+			indices = append(indices, uint64(i))
+		}
+	}
+	for _, i := range indices {
+		b.Cells = append(b.Cells, cellProof{Index: i, Cell: cells[i][:], Proof: proofs[i][:]})
+	}
+	return b
+}
+
+func (b *blobCells) unpack() (kzg4844.Commitment, []uint64, []Cell, []kzg4844.Proof, error) {
+	var commitment kzg4844.Commitment
+	if len(b.Commitment) != len(commitment) {
+		return commitment, nil, nil, nil, errors.New("invalid commitment")
+	}
+	copy(commitment[:], b.Commitment)
+	if kZGToVersionedHash(commitment) != b.VersionedHash {
+		return commitment, nil, nil, nil, errVersionedHashMismatch
+	}
+	var (
+		indices = make([]uint64, len(b.Cells))
+		cells   = make([]Cell, len(b.Cells))
+		proofs  = make([]kzg4844.Proof, len(b.Cells))
+	)
+	for i, c := range b.Cells {
+		if len(c.Cell) != bytesPerCell {
+			return commitment, nil, nil, nil, fmt.Errorf("invalid length of cell %d", c.Index)
+		}
+		if len(c.Proof) != len(proofs[i]) {
+			return commitment, nil, nil, nil, fmt.Errorf("invalid proof of cell %d", c.Index)
+		}
+		indices[i] = c.Index
+		copy(cells[i][:], c.Cell)
+		copy(proofs[i][:], c.Proof)
+	}
+	return commitment, indices, cells, proofs, nil
+}
+
+func readBlobCells(name string) ([]blobCells, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cells file: %v", err)
+	}
+	var cells []blobCells
+	if err := json.Unmarshal(data, &cells); err != nil {
+		return nil, fmt.Errorf("%w: invalid cells file", err)
+	}
+	return cells, nil
+}
+
+// parseCellIndices parses a comma separated list of cell indices
+func parseCellIndices(s string) ([]uint64, error) {
+	var indices []uint64
+	for _, f := range strings.Split(s, ",") {
+		i, err := strconv.ParseUint(strings.TrimSpace(f), 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid cell index %q", err, f)
+		}
+		if i >= cellsPerExtBlob {
+			return nil, fmt.Errorf("invalid cell index %d", i)
+		}
+		indices = append(indices, i)
+	}
+	return indices, nil
+}
+
+//go:embed resources/trusted_setup.json
+var trustedSetupJSON []byte
+
+// cellSetup holds the points of the trusted setup used for cell proofs
+type cellSetup struct {
+	// g1Lagrange are the Lagrange points in natural order
+	g1Lagrange []bls12381.G1Affine
+	g2Gen      bls12381.G2Affine
+	// g2Tau64 is [tau^FIELD_ELEMENTS_PER_CELL]_2
+	g2Tau64 bls12381.G2Affine
+}
+
+var (
+	cellSetupOnce   sync.Once
+	cellSetupErr    error
+	loadedCellSetup *cellSetup
+)
+
+func loadCellSetup() (*cellSetup, error) {
+	cellSetupOnce.Do(func() {
+		var ts struct {
+			G1Lagrange []hexutil.Bytes `json:"g1_lagrange"`
+			G2Monomial []hexutil.Bytes `json:"g2_monomial"`
+		}
+		if cellSetupErr = json.Unmarshal(trustedSetupJSON, &ts); cellSetupErr != nil {
+			return
+		}
+		if len(ts.G1Lagrange) != params.BlobTxFieldElementsPerBlob || len(ts.G2Monomial) <= fieldElementsPerCell {
+			cellSetupErr = errors.New("invalid trusted setup")
+			return
+		}
+		s := &cellSetup{g1Lagrange: make([]bls12381.G1Affine, len(ts.G1Lagrange))}
+		for i, b := range ts.G1Lagrange {
+			if _, cellSetupErr = s.g1Lagrange[i].SetBytes(b); cellSetupErr != nil {
+				return
+			}
+		}
+		if _, cellSetupErr = s.g2Gen.SetBytes(ts.G2Monomial[0]); cellSetupErr != nil {
+			return
+		}
+		if _, cellSetupErr = s.g2Tau64.SetBytes(ts.G2Monomial[fieldElementsPerCell]); cellSetupErr != nil {
+			return
+		}
+		loadedCellSetup = s
+	})
+	return loadedCellSetup, cellSetupErr
+}
+
+var (
+	extDomainOnce  sync.Once
+	extDomainRoots []fr.Element
+)
+
+// extDomain returns the FIELD_ELEMENTS_PER_EXT_BLOB roots of unity in bit-reversal order. Cell i is evaluated over
+// extDomain()[64*i : 64*(i+1)].
+func extDomain() []fr.Element {
+	extDomainOnce.Do(func() {
+		w := rootOfUnity(fieldElementsPerExtBlob)
+		extDomainRoots = make([]fr.Element, fieldElementsPerExtBlob)
+		extDomainRoots[0].SetOne()
+		for i := 1; i < len(extDomainRoots); i++ {
+			extDomainRoots[i].Mul(&extDomainRoots[i-1], &w)
+		}
+		bitReverse(extDomainRoots)
+	})
+	return extDomainRoots
+}
+
+// cosetVanishingConstant returns h^64 for the coset h*{64th roots of unity} of a cell, whose vanishing polynomial is
+// X^64 - h^64
+func cosetVanishingConstant(cellIndex int) fr.Element {
+	h := extDomain()[cellIndex*fieldElementsPerCell]
+	var a fr.Element
+	a.Exp(h, big.NewInt(fieldElementsPerCell))
+	return a
+}
+
+// ComputeCellsAndKZGProofs extends the blob to CELLS_PER_EXT_BLOB cells and computes the KZG proof of each cell
+func ComputeCellsAndKZGProofs(blob kzg4844.Blob) ([]Cell, []kzg4844.Proof, error) {
+	coefficients, err := blobCoefficients(blob[:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return cellsAndProofs(coefficients)
+}
+
+// cellsAndProofs computes the cells and cell proofs of the polynomial with the given coefficients
+func cellsAndProofs(coefficients []fr.Element) ([]Cell, []kzg4844.Proof, error) {
+	s, err := loadCellSetup()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: unable to load trusted setup", err)
+	}
+	cells := polynomialCells(coefficients)
+
+	// The proof of a cell commits to the quotient q of the polynomial p divided by the vanishing polynomial X^64 - a
+	// of its coset, whose coefficients are q[n] = sum(a^(t-1) * p[n+64t]) for t >= 1. With C_t the commitment to p
+	// shifted down by 64t coefficients, the proof is sum(a^(t-1) * C_t). The a of cell i is the 128th root of unity
+	// w^rev(i), so the proofs are the bit-reversed FFT of C_1, ..., C_63 over the 128th roots of unity.
+	shifted := make([]bls12381.G1Jac, cellsPerExtBlob)
+	for t := 1; t < params.BlobTxFieldElementsPerBlob/fieldElementsPerCell; t++ {
+		c := make([]fr.Element, params.BlobTxFieldElementsPerBlob)
+		copy(c, coefficients[t*fieldElementsPerCell:])
+		commitment, err := s.commit(c)
+		if err != nil {
+			return nil, nil, err
+		}
+		shifted[t-1].FromAffine(commitment)
+	}
+	g1FFT(shifted, rootOfUnity(cellsPerExtBlob))
+	proofs := make([]kzg4844.Proof, cellsPerExtBlob)
+	for i := range proofs {
+		var proof bls12381.G1Affine
+		proof.FromJacobian(&shifted[i])
+		proofs[i] = proof.Bytes()
+	}
+	bitReverseProofs(proofs)
+	return cells, proofs, nil
+}
+
+func bitReverseProofs(proofs []kzg4844.Proof) {
+	shift := 64 - bits.TrailingZeros(uint(len(proofs)))
+	for i := range proofs {
+		j := int(bits.Reverse64(uint64(i)) >> shift)
+		if i < j {
+			proofs[i], proofs[j] = proofs[j], proofs[i]
+		}
+	}
+}
+
+// g1FFT evaluates the polynomial with the given G1 coefficients at the powers of w, like fftWithRoot
+func g1FFT(values []bls12381.G1Jac, w fr.Element) {
+	n := len(values)
+	shift := 64 - bits.TrailingZeros(uint(n))
+	for i := range values {
+		j := int(bits.Reverse64(uint64(i)) >> shift)
+		if i < j {
+			values[i], values[j] = values[j], values[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		half := size / 2
+		var step fr.Element
+		step.Exp(w, big.NewInt(int64(n/size)))
+		twiddles := make([]big.Int, half)
+		var twiddle fr.Element
+		twiddle.SetOne()
+		for i := range twiddles {
+			twiddle.BigInt(&twiddles[i])
+			twiddle.Mul(&twiddle, &step)
+		}
+		for start := 0; start < n; start += size {
+			for j := 0; j < half; j++ {
+				var t bls12381.G1Jac
+				t.ScalarMultiplication(&values[start+j+half], &twiddles[j])
+				u := values[start+j]
+				values[start+j].AddAssign(&t)
+				values[start+j+half].Set(&u).SubAssign(&t)
+			}
+		}
+	}
+}
+
+// polynomialCells evaluates the polynomial over the extended domain in bit-reversed order and splits the evaluations
+// into cells
+func polynomialCells(coefficients []fr.Element) []Cell {
+	values := make([]fr.Element, fieldElementsPerExtBlob)
+	copy(values, coefficients)
+	fft(values)
+	bitReverse(values)
+	cells := make([]Cell, cellsPerExtBlob)
+	for i := range values {
+		b := values[i].Bytes()
+		copy(cells[i/fieldElementsPerCell][(i%fieldElementsPerCell)*32:], b[:])
+	}
+	return cells
+}
+
+// commit commits to a polynomial of degree below FIELD_ELEMENTS_PER_BLOB by evaluating it over the domain and using
+// the Lagrange points. The coefficients are overwritten.
+func (s *cellSetup) commit(coefficients []fr.Element) (*bls12381.G1Affine, error) {
+	fft(coefficients)
+	return new(bls12381.G1Affine).MultiExp(s.g1Lagrange, coefficients, ecc.MultiExpConfig{})
+}
+
+// VerifyCellKZGProofBatch verifies the proofs of cells of the blobs with the given commitments
+func VerifyCellKZGProofBatch(commitments []kzg4844.Commitment, cellIndices []uint64, cells []Cell, proofs []kzg4844.Proof) error {
+	if len(commitments) != len(cells) || len(cellIndices) != len(cells) || len(proofs) != len(cells) {
+		return errors.New("mismatched number of commitments, cell indices, cells and proofs")
+	}
+	if len(cells) == 0 {
+		return nil
+	}
+	s, err := loadCellSetup()
+	if err != nil {
+		return fmt.Errorf("%w: unable to load trusted setup", err)
+	}
+
+	// Each proof p of a cell with interpolation I over the coset vanishing on X^64 - a satisfies
+	// e(p, [tau^64 - a]_2) = e(C - [I(tau)]_1, [1]_2). The checks are combined with random powers r^k into
+	// e(sum(r^k p_k), [tau^64]_2) = e(sum(r^k (C_k + a_k p_k)) - [sum(r^k I_k)(tau)]_1, [1]_2).
+	var r fr.Element
+	if _, err := r.SetRandom(); err != nil {
+		return err
+	}
+	var (
+		points       = make([]bls12381.G1Affine, 0, 2*len(cells))
+		scalars      = make([]fr.Element, 0, 2*len(cells))
+		proofPoints  = make([]bls12381.G1Affine, len(cells))
+		powers       = make([]fr.Element, len(cells))
+		interpolated = make([]fr.Element, params.BlobTxFieldElementsPerBlob)
+		power        fr.Element
+	)
+	power.SetOne()
+	for k := range cells {
+		if cellIndices[k] >= cellsPerExtBlob {
+			return fmt.Errorf("invalid cell index %d", cellIndices[k])
+		}
+		var c bls12381.G1Affine
+		if _, err := c.SetBytes(commitments[k][:]); err != nil {
+			return fmt.Errorf("%w: invalid commitment of cell %d", err, cellIndices[k])
+		}
+		if _, err := proofPoints[k].SetBytes(proofs[k][:]); err != nil {
+			return fmt.Errorf("%w: invalid proof of cell %d", err, cellIndices[k])
+		}
+		interpolation, err := cellInterpolation(int(cellIndices[k]), cells[k])
+		if err != nil {
+			return fmt.Errorf("%w: cell %d", err, cellIndices[k])
+		}
+		for i := range interpolation {
+			var t fr.Element
+			t.Mul(&interpolation[i], &power)
+			interpolated[i].Add(&interpolated[i], &t)
+		}
+		a := cosetVanishingConstant(int(cellIndices[k]))
+		var ra fr.Element
+		ra.Mul(&a, &power)
+		points = append(points, c, proofPoints[k])
+		scalars = append(scalars, power, ra)
+		powers[k] = power
+		power.Mul(&power, &r)
+	}
+
+	var lhs, rhs bls12381.G1Affine
+	if _, err := lhs.MultiExp(proofPoints, powers, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	if _, err := rhs.MultiExp(points, scalars, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	commitment, err := s.commit(interpolated)
+	if err != nil {
+		return err
+	}
+	rhs.Sub(&rhs, commitment)
+	rhs.Neg(&rhs)
+	ok, err := bls12381.PairingCheck([]bls12381.G1Affine{lhs, rhs}, []bls12381.G2Affine{s.g2Tau64, s.g2Gen})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errInvalidCellProof
+	}
+	return nil
+}
+
+var errInvalidCellProof = errors.New("invalid cell proof")
+
+// cellInterpolation returns the coefficients of the polynomial of degree below 64 that interpolates the cell over its
+// coset h*{64th roots of unity}
+func cellInterpolation(cellIndex int, cell Cell) ([]fr.Element, error) {
+	values := make([]fr.Element, fieldElementsPerCell)
+	for i := range values {
+		if err := values[i].SetBytesCanonical(cell[i*32 : (i+1)*32]); err != nil {
+			return nil, fmt.Errorf("%w: invalid field element %d", err, i)
+		}
+	}
+	// the evaluations at h*w^j in natural order give the coefficients of I(hX)
+	bitReverse(values)
+	inverseFFT(values)
+	h := extDomain()[cellIndex*fieldElementsPerCell]
+	var hInv, shift fr.Element
+	hInv.Inverse(&h)
+	shift.SetOne()
+	for i := range values {
+		values[i].Mul(&values[i], &shift)
+		shift.Mul(&shift, &hInv)
+	}
+	return values, nil
+}
+
+// RecoverCellsAndKZGProofs recovers all cells of a blob and their proofs from at least half of them
+func RecoverCellsAndKZGProofs(cellIndices []uint64, cells []Cell) ([]Cell, []kzg4844.Proof, error) {
+	coefficients, err := recoverPolynomial(cellIndices, cells)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cellsAndProofs(coefficients)
+}
+
+// recoverPolynomial returns the coefficients of the blob polynomial. With E the extended evaluations, zero where cells
+// are missing, and Z the polynomial vanishing on the cosets of the missing cells, E*Z = P*Z over the whole extended
+// domain. P is then (P*Z)/Z evaluated over a coset of the domain, where Z has no roots.
+func recoverPolynomial(cellIndices []uint64, cells []Cell) ([]fr.Element, error) {
+	if len(cellIndices) != len(cells) {
+		return nil, errors.New("mismatched number of cell indices and cells")
+	}
+	present := make(map[uint64]bool)
+	extended := make([]fr.Element, fieldElementsPerExtBlob)
+	for i, index := range cellIndices {
+		if index >= cellsPerExtBlob {
+			return nil, fmt.Errorf("invalid cell index %d", index)
+		}
+		if present[index] {
+			return nil, fmt.Errorf("duplicate cell %d", index)
+		}
+		present[index] = true
+		for j := 0; j < fieldElementsPerCell; j++ {
+			fe := cells[i][j*32 : (j+1)*32]
+			if err := extended[int(index)*fieldElementsPerCell+j].SetBytesCanonical(fe); err != nil {
+				return nil, fmt.Errorf("%w: invalid field element %d of cell %d", err, j, index)
+			}
+		}
+	}
+	if len(present) < cellsPerExtBlob/2 {
+		return nil, fmt.Errorf("at least %d cells are needed to recover a blob, got %d", cellsPerExtBlob/2, len(present))
+	}
+	if len(present) == cellsPerExtBlob {
+		bitReverse(extended)
+		inverseFFT(extended)
+		return extended[:params.BlobTxFieldElementsPerBlob], nil
+	}
+
+	// Z(X) = prod(X^64 - a_j) over the missing cells j, computed as a polynomial in X^64
+	zY := []fr.Element{*new(fr.Element).SetOne()}
+	for i := 0; i < cellsPerExtBlob; i++ {
+		if present[uint64(i)] {
+			continue
+		}
+		a := cosetVanishingConstant(i)
+		next := make([]fr.Element, len(zY)+1)
+		for k := range zY {
+			var t fr.Element
+			t.Mul(&zY[k], &a)
+			next[k].Sub(&next[k], &t)
+			next[k+1].Add(&next[k+1], &zY[k])
+		}
+		zY = next
+	}
+	z := make([]fr.Element, fieldElementsPerExtBlob)
+	for k := range zY {
+		z[k*fieldElementsPerCell] = zY[k]
+	}
+	zEvals := append([]fr.Element{}, z...)
+	fft(zEvals)
+
+	// E*Z in natural order, interpolated to the coefficients of P*Z
+	bitReverse(extended)
+	for i := range extended {
+		extended[i].Mul(&extended[i], &zEvals[i])
+	}
+	inverseFFT(extended)
+
+	// divide over the coset shifted by the primitive root of unity
+	var shift fr.Element
+	shift.SetUint64(primitiveRootOfUnity)
+	scalePowers(extended, shift)
+	scalePowers(z, shift)
+	fft(extended)
+	fft(z)
+	z = fr.BatchInvert(z)
+	for i := range extended {
+		extended[i].Mul(&extended[i], &z[i])
+	}
+	inverseFFT(extended)
+	shift.Inverse(&shift)
+	scalePowers(extended, shift)
+
+	for i := params.BlobTxFieldElementsPerBlob; i < len(extended); i++ {
+		if !extended[i].IsZero() {
+			return nil, errors.New("cells are not the evaluations of a blob")
+		}
+	}
+	return extended[:params.BlobTxFieldElementsPerBlob], nil
+}
+
+// scalePowers multiplies the ith coefficient by s^i, which evaluates the polynomial at s*X
+func scalePowers(coefficients []fr.Element, s fr.Element) {
+	var power fr.Element
+	power.SetOne()
+	for i := range coefficients {
+		coefficients[i].Mul(&coefficients[i], &power)
+		power.Mul(&power, &s)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+var (
+	testCellsOnce       sync.Once
+	testCellsBlob       kzg4844.Blob
+	testCellsCommitment kzg4844.Commitment
+	testCells           []Cell
+	testCellProofs      []kzg4844.Proof
+	testCellsErr        error
+)
+
+// computeTestCells returns a blob with its commitment, cells and cell proofs, which are only computed once as they are
+// slow to compute
+func computeTestCells(t *testing.T) (kzg4844.Blob, kzg4844.Commitment, []Cell, []kzg4844.Proof) {
+	testCellsOnce.Do(func() {
+		var (
+			blobs       []kzg4844.Blob
+			commitments []kzg4844.Commitment
+		)
+		if blobs, commitments, _, _, testCellsErr = EncodeBlobs(makeBlob(losslessBlobCapacity), losslessCodec{}); testCellsErr != nil {
+			return
+		}
+		testCellsBlob, testCellsCommitment = blobs[0], commitments[0]
+		testCells, testCellProofs, testCellsErr = ComputeCellsAndKZGProofs(testCellsBlob)
+	})
+	if testCellsErr != nil {
+		t.Fatal(testCellsErr)
+	}
+	return testCellsBlob, testCellsCommitment, testCells, testCellProofs
+}
+
+func TestCells(t *testing.T) {
+	blob, commitment, cells, proofs := computeTestCells(t)
+
+	s, err := loadCellSetup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	coefficients, err := blobCoefficients(blob[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := s.commit(coefficients)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Bytes() != commitment {
+		t.Fatalf("expected commitment %x, got %x", commitment, c.Bytes())
+	}
+
+	if len(cells) != cellsPerExtBlob || len(proofs) != cellsPerExtBlob {
+		t.Fatalf("expected %d cells, got %d", cellsPerExtBlob, len(cells))
+	}
+	// the first half of the cells is the blob itself
+	var firstHalf []byte
+	for _, cell := range cells[:cellsPerExtBlob/2] {
+		firstHalf = append(firstHalf, cell[:]...)
+	}
+	if !bytes.Equal(firstHalf, blob[:]) {
+		t.Fatal("the first half of the cells is not the blob")
+	}
+
+	indices := []uint64{0, 1, 64, 127}
+	batch := []Cell{cells[0], cells[1], cells[64], cells[127]}
+	batchProofs := []kzg4844.Proof{proofs[0], proofs[1], proofs[64], proofs[127]}
+	batchCommitments := []kzg4844.Commitment{commitment, commitment, commitment, commitment}
+	if err := VerifyCellKZGProofBatch(batchCommitments, indices, batch, batchProofs); err != nil {
+		t.Fatal(err)
+	}
+	batch[2][31] ^= 1
+	if err := VerifyCellKZGProofBatch(batchCommitments, indices, batch, batchProofs); !errors.Is(err, errInvalidCellProof) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	batch[2][31] ^= 1
+	indices[3] = 126
+	if err := VerifyCellKZGProofBatch(batchCommitments, indices, batch, batchProofs); !errors.Is(err, errInvalidCellProof) {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// recover from every other cell
+	var (
+		partialIndices []uint64
+		partial        []Cell
+	)
+	for i := 1; i < cellsPerExtBlob; i += 2 {
+		partialIndices = append(partialIndices, uint64(i))
+		partial = append(partial, cells[i])
+	}
+	recoveredCoefficients, err := recoverPolynomial(partialIndices, partial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovered := polynomialCells(recoveredCoefficients)
+	for i := range cells {
+		if recovered[i] != cells[i] {
+			t.Fatalf("cell %d was not recovered", i)
+		}
+	}
+	if _, err := recoverPolynomial(partialIndices[1:], partial[1:]); err == nil {
+		t.Fatal("expected recovery from less than half of the cells to fail")
+	}
+}
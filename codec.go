@@ -0,0 +1,302 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// BlobCodec converts between arbitrary data and blobs
+type BlobCodec interface {
+	// Encode packs data into as many blobs as needed
+	Encode(data []byte) ([]kzg4844.Blob, error)
+	// Decode unpacks the data of a single blob
+	Decode(blob []byte) ([]byte, error)
+}
+
+var blobCodecs = map[string]BlobCodec{
+	"legacy":   legacyCodec{},
+	"lossless": losslessCodec{},
+	"dense":    denseCodec{},
+	"op":       opCodec{},
+	"padded":   paddedCodec{},
+	"zksync":   zksyncCodec{},
+}
+
+func lookupBlobCodec(name string) (BlobCodec, error) {
+	codec, ok := blobCodecs[name]
+	if !ok {
+		var names []string
+		for name := range blobCodecs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown codec %s, expected one of %s", name, strings.Join(names, ", "))
+	}
+	return codec, nil
+}
+
+// legacyCodec packs 31 bytes of data into each field element and strips trailing zeros when decoding, so data
+// ending in zeros does not round trip
+type legacyCodec struct{}
+
+func (legacyCodec) Encode(data []byte) ([]kzg4844.Blob, error) {
+	return encodeBlobs(data), nil
+}
+
+func (legacyCodec) Decode(blob []byte) ([]byte, error) {
+	if len(blob) != params.BlobTxFieldElementsPerBlob*32 {
+		return nil, fmt.Errorf("invalid blob length %d", len(blob))
+	}
+	return DecodeBlob(blob), nil
+}
+
+const (
+	losslessCodecVersion = 0x01
+	// losslessBlobCapacity is the number of bytes of data that fit in a blob with the lossless codec
+	losslessBlobCapacity = (params.BlobTxFieldElementsPerBlob - 1) * 31
+)
+
+// losslessCodec stores the codec version and the exact length of the data in the first field element of each blob,
+// followed by 31 bytes of data in every other field element. The first byte of every field element is left zero.
+type losslessCodec struct{}
+
+func (losslessCodec) Encode(data []byte) ([]kzg4844.Blob, error) {
+	return chunkBlobs(data, losslessBlobCapacity, func(chunk []byte) kzg4844.Blob {
+		var blob kzg4844.Blob
+		blob[1] = losslessCodecVersion
+		binary.BigEndian.PutUint32(blob[2:6], uint32(len(chunk)))
+		for i := 0; len(chunk) != 0; i++ {
+			chunk = chunk[copy(blob[(i+1)*32+1:(i+2)*32], chunk):]
+		}
+		return blob
+	}), nil
+}
+
+func (losslessCodec) Decode(blob []byte) ([]byte, error) {
+	if len(blob) != params.BlobTxFieldElementsPerBlob*32 {
+		return nil, fmt.Errorf("invalid blob length %d", len(blob))
+	}
+	if blob[0] != 0 || blob[1] != losslessCodecVersion {
+		return nil, fmt.Errorf("unsupported lossless codec version %d", blob[1])
+	}
+	length := binary.BigEndian.Uint32(blob[2:6])
+	if length > losslessBlobCapacity {
+		return nil, fmt.Errorf("invalid data length %d", length)
+	}
+	if !isZero(blob[6:32]) {
+		return nil, errors.New("invalid lossless codec header")
+	}
+
+	data := make([]byte, 0, losslessBlobCapacity)
+	for i := 1; i < params.BlobTxFieldElementsPerBlob; i++ {
+		if blob[i*32] != 0 {
+			return nil, fmt.Errorf("invalid field element %d", i)
+		}
+		data = append(data, blob[i*32+1:(i+1)*32]...)
+	}
+	if !isZero(data[length:]) {
+		return nil, errors.New("blob has data past its length")
+	}
+	return data[:length], nil
+}
+
+const (
+	denseCodecVersion = 0x02
+	// denseBlobCapacity is the number of bytes of data that fit in a blob with the dense codec, after the version byte
+	// and 4 byte length
+	denseBlobCapacity = packedBlobCapacity - 5
+)
+
+// denseCodec packs the codec version, the exact length of the data and the data itself into all 254 usable bits of
+// every field element
+type denseCodec struct{}
+
+func (denseCodec) Encode(data []byte) ([]kzg4844.Blob, error) {
+	return chunkBlobs(data, denseBlobCapacity, func(chunk []byte) kzg4844.Blob {
+		stream := make([]byte, 5, 5+len(chunk))
+		stream[0] = denseCodecVersion
+		binary.BigEndian.PutUint32(stream[1:5], uint32(len(chunk)))
+		return packBlob(append(stream, chunk...))
+	}), nil
+}
+
+func (denseCodec) Decode(blob []byte) ([]byte, error) {
+	stream, err := unpackBlob(blob)
+	if err != nil {
+		return nil, err
+	}
+	if stream[0] != denseCodecVersion {
+		return nil, fmt.Errorf("unsupported dense codec version %d", stream[0])
+	}
+	length := binary.BigEndian.Uint32(stream[1:5])
+	if length > denseBlobCapacity {
+		return nil, fmt.Errorf("invalid data length %d", length)
+	}
+	data := stream[5:]
+	if !isZero(data[length:]) {
+		return nil, errors.New("blob has data past its length")
+	}
+	return data[:length], nil
+}
+
+const (
+	opCodecVersion = 0x00
+	// opBlobCapacity is MaxBlobDataSize of the OP Stack blob encoding, what's left after the version byte and 3 byte
+	// length
+	opBlobCapacity = packedBlobCapacity - 4
+)
+
+// opCodec implements BlobEncodingVersion0 of the OP Stack, used by the batcher to post channel frames to blobs. It is
+// the dense packing with a version byte of 0 and a 3 byte length.
+type opCodec struct{}
+
+func (opCodec) Encode(data []byte) ([]kzg4844.Blob, error) {
+	return chunkBlobs(data, opBlobCapacity, func(chunk []byte) kzg4844.Blob {
+		stream := make([]byte, 4, 4+len(chunk))
+		stream[0] = opCodecVersion
+		stream[1] = byte(len(chunk) >> 16)
+		stream[2] = byte(len(chunk) >> 8)
+		stream[3] = byte(len(chunk))
+		return packBlob(append(stream, chunk...))
+	}), nil
+}
+
+func (opCodec) Decode(blob []byte) ([]byte, error) {
+	stream, err := unpackBlob(blob)
+	if err != nil {
+		return nil, err
+	}
+	if stream[0] != opCodecVersion {
+		return nil, fmt.Errorf("unsupported op codec version %d", stream[0])
+	}
+	length := int(stream[1])<<16 | int(stream[2])<<8 | int(stream[3])
+	if length > opBlobCapacity {
+		return nil, fmt.Errorf("invalid data length %d", length)
+	}
+	data := stream[4:]
+	if !isZero(data[length:]) {
+		return nil, errors.New("blob has data past its length")
+	}
+	return data[:length], nil
+}
+
+// paddedBlobCapacity is the number of bytes in the lower 31 bytes of every field element of a blob
+const paddedBlobCapacity = params.BlobTxFieldElementsPerBlob * 31
+
+// paddedCodec fills the lower 31 bytes of every field element with data and leaves the first byte zero, without
+// recording the length of the data. Arbitrum and Scroll use it for payloads that carry their own length, so decoding
+// returns the whole capacity of the blob.
+type paddedCodec struct{}
+
+func (paddedCodec) Encode(data []byte) ([]kzg4844.Blob, error) {
+	return chunkBlobs(data, paddedBlobCapacity, func(chunk []byte) kzg4844.Blob {
+		var blob kzg4844.Blob
+		for i := 0; len(chunk) != 0; i++ {
+			chunk = chunk[copy(blob[i*32+1:(i+1)*32], chunk):]
+		}
+		return blob
+	}), nil
+}
+
+func (paddedCodec) Decode(blob []byte) ([]byte, error) {
+	if len(blob) != params.BlobTxFieldElementsPerBlob*32 {
+		return nil, fmt.Errorf("invalid blob length %d", len(blob))
+	}
+	data := make([]byte, 0, paddedBlobCapacity)
+	for i := 0; i < params.BlobTxFieldElementsPerBlob; i++ {
+		if blob[i*32] != 0 {
+			return nil, fmt.Errorf("invalid field element %d", i)
+		}
+		data = append(data, blob[i*32+1:(i+1)*32]...)
+	}
+	return data, nil
+}
+
+// chunkBlobs splits data into chunks of at most capacity bytes and encodes each chunk into its own blob. Empty data is
+// encoded into a single blob.
+func chunkBlobs(data []byte, capacity int, encode func(chunk []byte) kzg4844.Blob) []kzg4844.Blob {
+	var blobs []kzg4844.Blob
+	for {
+		chunk := data
+		if len(chunk) > capacity {
+			chunk = chunk[:capacity]
+		}
+		data = data[len(chunk):]
+		blobs = append(blobs, encode(chunk))
+		if len(data) == 0 {
+			return blobs
+		}
+	}
+}
+
+const (
+	// packedRoundSize is the number of bytes packed into a round of 4 field elements: 31 bytes in the lower bytes of each
+	// field element plus 3 bytes split into the lower 6 bits of their first bytes
+	packedRoundSize = 4*31 + 3
+	packedRounds    = params.BlobTxFieldElementsPerBlob / 4
+	// packedBlobCapacity is the number of bytes that fit in a blob using 254 bits of every field element
+	packedBlobCapacity = packedRounds * packedRoundSize
+)
+
+// packBlob packs up to packedBlobCapacity bytes into a blob. This is the packing used by the OP Stack blob encoding.
+func packBlob(stream []byte) kzg4844.Blob {
+	var blob kzg4844.Blob
+	for r := 0; len(stream) != 0; r++ {
+		var round [packedRoundSize]byte
+		stream = stream[copy(round[:], stream):]
+
+		fe := blob[r*128 : (r+1)*128]
+		copy(fe[1:32], round[0:31])
+		copy(fe[33:64], round[32:63])
+		copy(fe[65:96], round[64:95])
+		copy(fe[97:128], round[96:127])
+		x, y, z := round[31], round[63], round[95]
+		fe[0] = x & 0b0011_1111
+		fe[32] = (y & 0b0000_1111) | (x&0b1100_0000)>>2
+		fe[64] = z & 0b0011_1111
+		fe[96] = (z&0b1100_0000)>>2 | (y&0b1111_0000)>>4
+	}
+	return blob
+}
+
+// unpackBlob reverses packBlob, returning all packedBlobCapacity bytes of the blob
+func unpackBlob(blob []byte) ([]byte, error) {
+	if len(blob) != params.BlobTxFieldElementsPerBlob*32 {
+		return nil, fmt.Errorf("invalid blob length %d", len(blob))
+	}
+	stream := make([]byte, 0, packedBlobCapacity)
+	for r := 0; r < packedRounds; r++ {
+		fe := blob[r*128 : (r+1)*128]
+		for i := 0; i < 4; i++ {
+			if fe[i*32]&0b1100_0000 != 0 {
+				return nil, fmt.Errorf("invalid field element %d", r*4+i)
+			}
+		}
+		x := fe[0]&0b0011_1111 | (fe[32]&0b0011_0000)<<2
+		y := fe[32]&0b0000_1111 | (fe[96]&0b0000_1111)<<4
+		z := fe[64]&0b0011_1111 | (fe[96]&0b0011_0000)<<2
+		stream = append(stream, fe[1:32]...)
+		stream = append(stream, x)
+		stream = append(stream, fe[33:64]...)
+		stream = append(stream, y)
+		stream = append(stream, fe[65:96]...)
+		stream = append(stream, z)
+		stream = append(stream, fe[97:128]...)
+	}
+	return stream, nil
+}
+
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
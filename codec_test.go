@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+func TestLosslessCodec(t *testing.T) {
+	testCodecRoundTrip(t, losslessCodec{}, losslessBlobCapacity)
+}
+
+func TestDenseCodec(t *testing.T) {
+	testCodecRoundTrip(t, denseCodec{}, denseBlobCapacity)
+}
+
+func TestOPCodec(t *testing.T) {
+	testCodecRoundTrip(t, opCodec{}, opBlobCapacity)
+
+	// version 0 and the 3 byte big endian length are at the start of the first field element
+	blobs, err := opCodec{}.Encode(makeBlob(0x010203))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(blobs[0][1:5], []byte{0x00, 0x01, 0x02, 0x03}) {
+		t.Fatalf("unexpected header %x", blobs[0][1:5])
+	}
+	if opBlobCapacity != 130044 {
+		t.Fatalf("unexpected capacity %d", opBlobCapacity)
+	}
+}
+
+func TestZKSyncCodec(t *testing.T) {
+	input := makeBlob(paddedBlobCapacity + 100)
+	blobs, err := zksyncCodec{}.Encode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec []byte
+	for _, blob := range blobs {
+		data, err := zksyncCodec{}.Decode(blob[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec = append(dec, data...)
+	}
+	// the last blob is padded with zeros
+	if !bytes.Equal(input, dec[:len(input)]) || !isZero(dec[len(input):]) {
+		t.Fatalf("expected %x, got %x", input, dec)
+	}
+	// the blob holds evaluations, not the coefficients themselves
+	if bytes.Equal(blobs[0][1:32], input[:31]) {
+		t.Fatal("pubdata was not transformed")
+	}
+}
+
+func testCodecRoundTrip(t *testing.T, codec BlobCodec, capacity int) {
+	trailingZeros := makeBlob(100)
+	for i := 90; i < len(trailingZeros); i++ {
+		trailingZeros[i] = 0
+	}
+	inputs := [][]byte{
+		makeBlob(0),
+		makeBlob(5),
+		trailingZeros,
+		make([]byte, 64),
+		makeBlob(capacity),
+		makeBlob(capacity*2 + 10),
+	}
+	for i, input := range inputs {
+		blobs, err := codec.Encode(input)
+		if err != nil {
+			t.Fatalf("(%d) encode: %v", i, err)
+		}
+		expected := (len(input) + capacity - 1) / capacity
+		if expected == 0 {
+			expected = 1
+		}
+		if len(blobs) != expected {
+			t.Fatalf("(%d) expected %d blobs, got %d", i, expected, len(blobs))
+		}
+		var dec []byte
+		for _, blob := range blobs {
+			if _, err := kzg4844.BlobToCommitment(blob); err != nil {
+				t.Fatalf("(%d) invalid blob: %v", i, err)
+			}
+			data, err := codec.Decode(blob[:])
+			if err != nil {
+				t.Fatalf("(%d) decode: %v", i, err)
+			}
+			dec = append(dec, data...)
+		}
+		if !bytes.Equal(input, dec) {
+			t.Fatalf("(%d) expected %x, got %x", i, input, dec)
+		}
+	}
+}
+
+func TestLosslessCodecInvalidBlob(t *testing.T) {
+	codec := losslessCodec{}
+	blobs, err := codec.Encode(makeBlob(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := blobs[0]
+	blob[1] = 0x7f
+	if _, err := codec.Decode(blob[:]); err == nil {
+		t.Fatal("expected unsupported version error")
+	}
+
+	blob = blobs[0]
+	blob[32*100+5] = 1
+	if _, err := codec.Decode(blob[:]); err == nil {
+		t.Fatal("expected error for data past the encoded length")
+	}
+}
+
+func TestValidateBlobs(t *testing.T) {
+	blobs, err := splitRawBlobs(make([]byte, 2*blobSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateBlobs(blobs); err != nil {
+		t.Fatal(err)
+	}
+	// the modulus itself is the smallest non-canonical value
+	modulus := fr.Modulus().FillBytes(make([]byte, 32))
+	copy(blobs[1][7*32:], modulus)
+	err = validateBlobs(blobs)
+	if !errors.Is(err, errNonCanonicalFieldElement) || err.Error() != errNonCanonicalFieldElement.Error()+": blob 1 field element 7" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, _, _, err := CommitBlobs(blobs); !errors.Is(err, errNonCanonicalFieldElement) {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if _, err := splitRawBlobs(make([]byte, blobSize+1)); err == nil {
+		t.Fatal("expected a partial blob to fail")
+	}
+}
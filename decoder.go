@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// BlobDecoder interprets the payloads of downloaded blobs, e.g. as the batches of a rollup
+type BlobDecoder interface {
+	// Codec returns the codec of the rollup, which decodes the blobs unless another one is requested
+	Codec() BlobCodec
+	// AddBlob is called with the decoded payload of every blob, in the order of the blobs of a block and of the
+	// blocks. A payload spread across blobs may be buffered until its last blob is added. The data is decoded anew
+	// for every call, so the decoder may keep references to it.
+	AddBlob(data []byte) error
+	// Flush is called once there are no more blobs
+	Flush() error
+}
+
+var blobDecoders = map[string]func(out io.Writer) BlobDecoder{
+	"op-frames": func(out io.Writer) BlobDecoder { return newOPFrameDecoder(out) },
+	"arbitrum":  func(out io.Writer) BlobDecoder { return newArbitrumDecoder(out) },
+	"taiko":     func(out io.Writer) BlobDecoder { return newTaikoDecoder(out) },
+	"scroll":    func(out io.Writer) BlobDecoder { return newScrollDecoder(out) },
+	"zksync":    func(out io.Writer) BlobDecoder { return newZKSyncDecoder(out) },
+}
+
+func lookupBlobDecoder(name string, out io.Writer) (BlobDecoder, error) {
+	newDecoder, ok := blobDecoders[name]
+	if !ok {
+		names := make([]string, 0, len(blobDecoders))
+		for name := range blobDecoders {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown format %s, expected one of %s", name, strings.Join(names, ", "))
+	}
+	return newDecoder(out), nil
+}
+
+// byteReader reads big endian values from a byte slice. The first error is sticky and zero values are returned once
+// it's set.
+type byteReader struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+func (r *byteReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data)-r.offset {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := r.data[r.offset : r.offset+n]
+	r.offset += n
+	return b
+}
+
+func (r *byteReader) uint8() uint8 {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *byteReader) uint16() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *byteReader) uint24() uint32 {
+	if b := r.bytes(3); b != nil {
+		return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+	}
+	return 0
+}
+
+func (r *byteReader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *byteReader) uint64() uint64 {
+	if b := r.bytes(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
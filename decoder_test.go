@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestArbitrumDecoder(t *testing.T) {
+	advance, err := rlp.EncodeToBytes(uint64(12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an incompressible message makes the batch span two blobs
+	large := make([]byte, paddedBlobCapacity)
+	rand.New(rand.NewSource(1)).Read(large)
+	var segments []byte
+	for _, segment := range [][]byte{
+		{arbitrumSegmentKindL2Message, 4, 0xaa, 0xbb},
+		append([]byte{arbitrumSegmentKindL2Message, 3}, large...),
+		append([]byte{arbitrumSegmentKindAdvanceTimestamp}, advance...),
+		{arbitrumSegmentKindDelayedMessages},
+	} {
+		b, err := rlp.EncodeToBytes(segment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		segments = append(segments, b...)
+	}
+	compressed := bytes.NewBuffer([]byte{arbitrumBrotliMessageHeaderByte})
+	bw := brotli.NewWriter(compressed)
+	bw.Write(segments)
+	bw.Close()
+	encoded, err := rlp.EncodeToBytes(compressed.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs, err := paddedCodec{}.Encode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blobs) != 2 {
+		t.Fatalf("expected 2 blobs, got %d", len(blobs))
+	}
+
+	var out bytes.Buffer
+	d := newArbitrumDecoder(&out)
+	for _, blob := range blobs {
+		data, err := d.Codec().Decode(blob[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := d.AddBlob(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var batch arbitrumBatch
+	if err := json.Unmarshal(out.Bytes(), &batch); err != nil {
+		t.Fatal(err)
+	}
+	s := batch.Segments
+	if len(s) != 4 || s[0].Kind != "l2_message" || s[0].L2Kind != "signed_tx" || !bytes.Equal(s[0].Data, []byte{0xaa, 0xbb}) ||
+		s[1].L2Kind != "batch" || !bytes.Equal(s[1].Data, large) || s[2].Kind != "advance_timestamp" || s[2].Advance != 12 ||
+		s[3].Kind != "delayed_messages" {
+		t.Fatalf("unexpected segments %+v", s)
+	}
+}
+
+func TestTaikoDecoder(t *testing.T) {
+	legacyTx, err := rlp.EncodeToBytes([]any{uint64(1), uint64(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	typedTx := []byte{0x02, 0xc1, 0x80}
+	var data []byte
+	for _, txs := range [][]any{{legacyTx, typedTx}, {typedTx}} {
+		list := make([]any, 0, len(txs))
+		for _, tx := range txs {
+			if b := tx.([]byte); b[0] >= 0xc0 {
+				list = append(list, rlp.RawValue(b))
+			} else {
+				list = append(list, b)
+			}
+		}
+		b, err := rlp.EncodeToBytes(list)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		zw.Write(b)
+		zw.Close()
+		data = append(data, buf.Bytes()...)
+	}
+	blobs, err := opCodec{}.Encode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := opCodec{}.Decode(blobs[0][:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := newTaikoDecoder(&out).AddBlob(append(decoded, make([]byte, 64)...)); err != nil {
+		t.Fatal(err)
+	}
+	var blob taikoBlob
+	if err := json.Unmarshal(out.Bytes(), &blob); err != nil {
+		t.Fatal(err)
+	}
+	if len(blob.TxLists) != 2 || len(blob.TxLists[0]) != 2 || len(blob.TxLists[1]) != 1 ||
+		!bytes.Equal(blob.TxLists[0][0], legacyTx) || !bytes.Equal(blob.TxLists[0][1], typedTx) {
+		t.Fatalf("unexpected tx lists %+v", blob.TxLists)
+	}
+}
+
+func TestScrollDecoder(t *testing.T) {
+	legacyTx, err := rlp.EncodeToBytes([]any{uint64(1), uint64(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	typedTx := []byte{0x02, 0xc1, 0x80}
+
+	payload := append(common.HexToHash("0x01").Bytes(), common.HexToHash("0x02").Bytes()...)
+	payload = binary.BigEndian.AppendUint64(payload, 1000)
+	payload = binary.BigEndian.AppendUint16(payload, 2)
+	for _, counts := range [][2]uint16{{2, 1}, {2, 0}} {
+		payload = binary.BigEndian.AppendUint64(payload, 1700000000)
+		payload = append(payload, common.HexToHash("0x07").Bytes()...)
+		payload = binary.BigEndian.AppendUint64(payload, 30_000_000)
+		payload = binary.BigEndian.AppendUint16(payload, counts[0])
+		payload = binary.BigEndian.AppendUint16(payload, counts[1])
+	}
+	payload = append(payload, legacyTx...)
+	payload = append(payload, typedTx...)
+	payload = append(payload, legacyTx...)
+
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed := enc.EncodeAll(payload, nil)[len(scrollZstdMagic):]
+
+	for name, envelope := range map[string][]byte{
+		"uncompressed": append([]byte{7, 0, byte(len(payload) >> 8), byte(len(payload)), 0}, payload...),
+		"compressed":   append([]byte{8, 0, byte(len(compressed) >> 8), byte(len(compressed)), 1}, compressed...),
+	} {
+		blobs, err := paddedCodec{}.Encode(envelope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := paddedCodec{}.Decode(blobs[0][:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		batch, err := decodeScrollBatch(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if batch.InitialL2BlockNumber != 1000 || len(batch.Blocks) != 2 || batch.Blocks[0].BaseFee.ToInt().Int64() != 7 ||
+			batch.PostL1MessageQueueHash != common.HexToHash("0x02") {
+			t.Fatalf("%s: unexpected batch %+v", name, batch)
+		}
+		txs := batch.L2Transactions
+		if len(txs) != 3 || !bytes.Equal(txs[0], legacyTx) || !bytes.Equal(txs[1], typedTx) || !bytes.Equal(txs[2], legacyTx) {
+			t.Fatalf("%s: unexpected transactions %x", name, txs)
+		}
+	}
+}
+
+func TestDecodeZKSyncPubdata(t *testing.T) {
+	b := binary.BigEndian.AppendUint32(nil, 1) // logs
+	b = append(b, 0, 1)
+	b = binary.BigEndian.AppendUint16(b, 3)
+	b = append(b, common.HexToAddress("0x8008").Bytes()...)
+	b = append(b, make([]byte, 64)...)
+	b = binary.BigEndian.AppendUint32(b, 1) // messages
+	b = binary.BigEndian.AppendUint32(b, 2)
+	b = append(b, 0xca, 0xfe)
+	b = binary.BigEndian.AppendUint32(b, 0) // bytecodes
+
+	diffs := binary.BigEndian.AppendUint16(nil, 1)
+	diffs = append(diffs, common.HexToHash("0x05").Bytes()...)
+	diffs = append(diffs, 2<<zksyncStateDiffOperationShift|1, 0x01, 0x00) // add 0x0100
+	diffs = append(diffs, 0, 0, 0, 9)                                     // enumeration index 9
+	diffs = append(diffs, 0)                                              // nothing, 32 byte value
+	diffs = append(diffs, make([]byte, 32)...)
+	b = append(b, zksyncStateDiffCompressionV1, 0, byte(len(diffs)>>8), byte(len(diffs)), 4)
+	b = append(b, diffs...)
+
+	if _, err := decodeZKSyncPubdata(b[:len(b)-1]); err == nil {
+		t.Fatal("expected truncated pubdata to fail")
+	}
+	p, err := decodeZKSyncPubdata(append(b, make([]byte, 100)...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.L2ToL1Logs) != 1 || !p.L2ToL1Logs[0].IsService || p.L2ToL1Logs[0].TxNumberInBlock != 3 ||
+		len(p.Messages) != 1 || !bytes.Equal(p.Messages[0], []byte{0xca, 0xfe}) {
+		t.Fatalf("unexpected pubdata %+v", p)
+	}
+	if len(p.InitialWrites) != 1 || p.InitialWrites[0].Operation != "add" || !bytes.Equal(p.InitialWrites[0].Value, []byte{1, 0}) ||
+		len(p.RepeatedWrites) != 1 || p.RepeatedWrites[0].EnumerationIndex != 9 || len(p.RepeatedWrites[0].Value) != 32 {
+		t.Fatalf("unexpected state diffs %+v %+v", p.InitialWrites, p.RepeatedWrites)
+	}
+}
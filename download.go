@@ -1,214 +1,375 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/urfave/cli"
 )
 
-func init() {
-	// Done to ensure that we are able to download blob chunks with larger chunk sizes (that is 10 MiB post-bellatrix)
-	// encoder.MaxChunkSize = 10 << 20
+// blobSidecarSource is implemented by the beacon node REST client and the libp2p downloader
+type blobSidecarSource interface {
+	BlobSidecars(ctx context.Context, blockID string, indices ...uint64) ([]*BlobSidecar, error)
+	// BlockRoot returns the root of the block the source reports for a block ID such as head or finalized
+	BlockRoot(ctx context.Context, blockID string) (common.Hash, error)
 }
 
 func DownloadApp(cliCtx *cli.Context) error {
-	// TODO: support downloading blobs from a beacon node.
-	return errors.New("unsupported for the latest spec")
-/*
-	addr := cliCtx.String(DownloadBeaconP2PAddr.Name)
-	slot := cliCtx.Int64(DownloadSlotFlag.Name)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	req := &ethpb.BlobsSidecarsByRangeRequest{
-		StartSlot: types.Slot(slot),
-		Count:     1,
-	}
-
-	h, err := libp2p.New(libp2p.Transport(tcp.NewTCPTransport))
+	addr := cliCtx.String(DownloadBeaconURLFlag.Name)
+	blockID := cliCtx.String(DownloadBlockIDFlag.Name)
+	outputDir := cliCtx.String(DownloadOutputDirFlag.Name)
+	codec, err := lookupBlobCodec(cliCtx.String(DownloadCodecFlag.Name))
 	if err != nil {
 		return err
 	}
-	defer func() {
-		_ = h.Close()
-	}()
-	// setup enough handlers so we look like a beacon peer
-	// Some clients, including lighthouse, expect a minimum set of protocols before completing
-	// a libp2p connection
-	setHandler(h, p2p.RPCPingTopicV1, pingHandler)
-	setHandler(h, p2p.RPCGoodByeTopicV1, pingHandler)
-	setHandler(h, p2p.RPCMetaDataTopicV1, pingHandler)
-	setHandler(h, p2p.RPCMetaDataTopicV2, pingHandler)
-
-	nilHandler := func(ctx context.Context, i interface{}, stream network.Stream) error {
-		return nil
+	writer := &blobWriter{outputDir: outputDir, codec: codec}
+	format := cliCtx.String(DownloadFormatFlag.Name)
+	if cliCtx.IsSet(DownloadDecodeFlag.Name) {
+		format = cliCtx.String(DownloadDecodeFlag.Name)
+	}
+	if format != "" {
+		if writer.decoder, err = lookupBlobDecoder(format, os.Stdout); err != nil {
+			return err
+		}
+		if !cliCtx.IsSet(DownloadCodecFlag.Name) {
+			writer.codec = writer.decoder.Codec()
+		}
+	}
+	if cliCtx.IsSet(DownloadSlotFlag.Name) {
+		blockID = strconv.FormatInt(cliCtx.Int64(DownloadSlotFlag.Name), 10)
 	}
-	setHandler(h, p2p.RPCBlocksByRangeTopicV1, nilHandler)
-	setHandler(h, p2p.RPCBlocksByRangeTopicV2, nilHandler)
-	setHandler(h, p2p.RPCBlobSidecarsByRootTopicV1, nilHandler)
 
-	multiaddr, err := getMultiaddr(ctx, h, addr)
-	if err != nil {
-		return fmt.Errorf("%w: unable to get multiaddr", err)
+	ctx := context.Background()
+	client := newBeaconClient(addr)
+
+	var source blobSidecarSource = client
+	if cliCtx.IsSet(DownloadBeaconP2PAddr.Name) {
+		if cliCtx.Bool(DownloadFollowFlag.Name) || cliCtx.IsSet(DownloadTxHashFlag.Name) {
+			return fmt.Errorf("--%s and --%s require the beacon node REST API", DownloadFollowFlag.Name, DownloadTxHashFlag.Name)
+		}
+		var forkDigest []byte
+		if cliCtx.IsSet(DownloadForkDigestFlag.Name) {
+			var err error
+			if forkDigest, err = hexutil.Decode(cliCtx.String(DownloadForkDigestFlag.Name)); err != nil || len(forkDigest) != 4 {
+				return fmt.Errorf("invalid fork digest %s", cliCtx.String(DownloadForkDigestFlag.Name))
+			}
+		}
+		peer, err := dialBeaconPeer(ctx, cliCtx.String(DownloadBeaconP2PAddr.Name), forkDigest)
+		if err != nil {
+			return err
+		}
+		defer peer.Close()
+		log.Printf("connected to beacon peer. peer=%v headSlot=%d forkDigest=%x", peer.id, peer.status.HeadSlot, peer.status.ForkDigest)
+		if peer.forks, err = client.ForkSchedule(ctx); err != nil {
+			log.Printf("unable to get the fork schedule, only accepting the fork digests of the status and --%s. err=%v", DownloadForkDigestFlag.Name, err)
+		}
+		source = peer
 	}
 
-	addrInfo, err := peer.AddrInfoFromP2pAddr(multiaddr)
-	if err != nil {
-		return fmt.Errorf("%w: unable to get addr info", err)
+	if cliCtx.Bool(DownloadFollowFlag.Name) {
+		follower, err := newBlobFollower(ctx, client, cliCtx.String(DownloadRPCURLFlag.Name), writer,
+			cliCtx.String(DownloadFromAddressFlag.Name), cliCtx.String(DownloadVersionedHashPrefixFlag.Name))
+		if err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+		if err := follower.run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+		return writer.Close()
 	}
 
-	err = h.Connect(ctx, *addrInfo)
-	if err != nil {
-		return fmt.Errorf("%w: failed to connect", err)
+	if cliCtx.IsSet(DownloadFromSlotFlag.Name) {
+		if !cliCtx.IsSet(DownloadToSlotFlag.Name) {
+			return fmt.Errorf("--%s is required with --%s", DownloadToSlotFlag.Name, DownloadFromSlotFlag.Name)
+		}
+		if outputDir == "" {
+			return fmt.Errorf("--%s is required with --%s", DownloadOutputDirFlag.Name, DownloadFromSlotFlag.Name)
+		}
+		// the range is only stored unless its blobs are decoded
+		if writer.decoder == nil {
+			return downloadSlotRange(ctx, source, outputDir, nil, cliCtx.Uint64(DownloadFromSlotFlag.Name), cliCtx.Uint64(DownloadToSlotFlag.Name))
+		}
+		if err := downloadSlotRange(ctx, source, outputDir, writer, cliCtx.Uint64(DownloadFromSlotFlag.Name), cliCtx.Uint64(DownloadToSlotFlag.Name)); err != nil {
+			return err
+		}
+		return writer.Close()
 	}
 
-	sidecars, err := sendBlobsSidecarsByRangeRequest(ctx, h, encoder.SszNetworkEncoder{}, addrInfo.ID, req)
+	var sidecars []*BlobSidecar
+	if cliCtx.IsSet(DownloadTxHashFlag.Name) {
+		txHash := common.HexToHash(cliCtx.String(DownloadTxHashFlag.Name))
+		sidecars, err = downloadTxBlobSidecars(ctx, client, cliCtx.String(DownloadRPCURLFlag.Name), txHash)
+	} else {
+		sidecars, err = downloadBlobSidecars(ctx, source, blockID)
+	}
 	if err != nil {
-		return fmt.Errorf("%w: unable to send blobs RPC request", err)
+		return err
 	}
 
-	anyBlobs := false
 	for _, sidecar := range sidecars {
-		if int64(sidecar.Slot) != slot {
-			break
+		if err := writer.Write(sidecar); err != nil {
+			return err
 		}
+	}
+	return writer.Close()
+}
 
-		if len(sidecar.Blob.Data) == 0 {
-			continue
+// downloadBlobSidecars fetches and verifies the blob sidecars of a block. Block IDs other than a slot or block root,
+// e.g. head or finalized, are resolved to the root of the block the source reports for them first, so the sidecars
+// can be checked against it.
+func downloadBlobSidecars(ctx context.Context, source blobSidecarSource, blockID string) ([]*BlobSidecar, error) {
+	if _, err := strconv.ParseUint(blockID, 10, 64); err != nil && !strings.HasPrefix(blockID, "0x") {
+		root, err := source.BlockRoot(ctx, blockID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get block root of %s", err, blockID)
 		}
+		log.Printf("resolved block. blockID=%s blockRoot=%v", blockID, root)
+		blockID = root.Hex()
+	}
+	sidecars, err := source.BlobSidecars(ctx, blockID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to fetch blob sidecars", err)
+	}
+	if len(sidecars) == 0 {
+		return nil, fmt.Errorf("no blobs found in block %s", blockID)
+	}
+	if err := verifyBlobSidecars(blockID, sidecars); err != nil {
+		return nil, err
+	}
+	return sidecars, nil
+}
 
-		anyBlobs = true
-		data := DecodeBlob(sidecar.Blob.Data)
-		_, _ = os.Stdout.Write(data)
-
-		// stop after the first sidecar with blobs:
-		break
+// downloadTxBlobSidecars fetches the blob sidecars of a blob transaction. The transaction is looked up on the execution
+// node to find the slot it was included in, and only the sidecars matching the transaction's blob hashes are returned.
+func downloadTxBlobSidecars(ctx context.Context, client *beaconClient, rpcURL string, txHash common.Hash) ([]*BlobSidecar, error) {
+	ethClient, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to connect to the Ethereum client", err)
 	}
+	defer ethClient.Close()
 
-	if !anyBlobs {
-		return fmt.Errorf("no blobs found in requested slots, sidecar count: %d", len(sidecars))
+	tx, pending, err := ethClient.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get transaction %v", err, txHash)
 	}
-	return nil
-	*/
-}
-/*
-func getMultiaddr(ctx context.Context, h host.Host, addr string) (ma.Multiaddr, error) {
-	multiaddr, err := ma.NewMultiaddr(addr)
+	if pending {
+		return nil, fmt.Errorf("transaction %v is still pending", txHash)
+	}
+	if tx.Type() != types.BlobTxType {
+		return nil, fmt.Errorf("transaction %v is not a blob transaction", txHash)
+	}
+	receipt, err := ethClient.TransactionReceipt(ctx, txHash)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: unable to get receipt of %v", err, txHash)
+	}
+	header, err := ethClient.HeaderByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get block %v", err, receipt.BlockHash)
 	}
-	_, id := peer.SplitAddr(multiaddr)
-	if id != "" {
-		return multiaddr, nil
+	slot, err := client.SlotAt(ctx, header.Time)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to compute slot of block %v", err, receipt.BlockHash)
 	}
-	// peer ID wasn't provided, look it up
-	id, err = retrievePeerID(ctx, h, addr)
+	log.Printf("found transaction. txhash=%v block=%d slot=%d blobs=%d", txHash, receipt.BlockNumber, slot, len(tx.BlobHashes()))
+
+	sidecars, err := downloadBlobSidecars(ctx, client, strconv.FormatUint(slot, 10))
 	if err != nil {
 		return nil, err
 	}
-	return ma.NewMultiaddr(fmt.Sprintf("%s/p2p/%s", addr, string(id)))
+	return matchTxBlobSidecars(tx, slot, sidecars)
 }
 
-// Helper for retrieving the peer ID from a security error... obviously don't use this in production!
-// See https://github.com/libp2p/go-libp2p-noise/blob/v0.3.0/handshake.go#L250
-func retrievePeerID(ctx context.Context, h host.Host, addr string) (peer.ID, error) {
-	incorrectPeerID := "16Uiu2HAmSifdT5QutTsaET8xqjWAMPp4obrQv7LN79f2RMmBe3nY"
-	addrInfo, err := peer.AddrInfoFromString(fmt.Sprintf("%s/p2p/%s", addr, incorrectPeerID))
-	if err != nil {
-		return "", err
-	}
-	err = h.Connect(ctx, *addrInfo)
-	if err == nil {
-		return "", errors.New("unexpected successful connection")
+// matchTxBlobSidecars returns the sidecars of the blobs of a transaction included in slot, in the order of its blob
+// hashes
+func matchTxBlobSidecars(tx *types.Transaction, slot uint64, sidecars []*BlobSidecar) ([]*BlobSidecar, error) {
+	byHash := make(map[common.Hash]*BlobSidecar)
+	for _, sidecar := range sidecars {
+		byHash[kZGToVersionedHash(sidecar.KZGCommitment)] = sidecar
 	}
-	if strings.Contains(err.Error(), "but remote key matches") {
-		split := strings.Split(err.Error(), " ")
-		return peer.ID(split[len(split)-1]), nil
+	var txSidecars []*BlobSidecar
+	for _, versionedHash := range tx.BlobHashes() {
+		sidecar, ok := byHash[versionedHash]
+		if !ok {
+			return nil, fmt.Errorf("blob %v of transaction %v not found in slot %d", versionedHash, tx.Hash(), slot)
+		}
+		txSidecars = append(txSidecars, sidecar)
 	}
-	return "", err
+	return txSidecars, nil
 }
 
-func sendBlobsSidecarsByRangeRequest(ctx context.Context, h host.Host, encoding encoder.NetworkEncoding, pid peer.ID, req *ethpb.BlobsSidecarsByRangeRequest) ([]*ethpb.BlobSidecar, error) {
-	const id = "/eth2/beacon_chain/req/blob_sidecars_by_range/1"
-	topic := fmt.Sprintf("%s%s", id, encoding.ProtocolSuffix())
-
-	stream, err := h.NewStream(ctx, pid, protocol.ID(topic))
+// downloadSlotRange downloads the blobs of every slot in [from, to] into the blob store at dir. Missed slots and slots
+// without blobs are skipped, as are the slots the store records as already downloaded, so an interrupted download
+// resumes where it stopped. The downloaded blobs are also passed to the writer if it isn't nil.
+func downloadSlotRange(ctx context.Context, source blobSidecarSource, dir string, writer *blobWriter, from, to uint64) error {
+	if from > to {
+		return fmt.Errorf("invalid slot range %d-%d", from, to)
+	}
+	store, err := openBlobStore(dir)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer func() {
-		_ = stream.Close()
-	}()
+	defer store.Close()
 
-	if _, err := encoding.EncodeWithMaxLength(stream, req); err != nil {
-		_ = stream.Reset()
-		return nil, err
+	downloaded, err := store.DownloadedSlots()
+	if err != nil {
+		return err
 	}
-
-	if err := stream.CloseWrite(); err != nil {
-		_ = stream.Reset()
-		return nil, err
+	var done uint64
+	for _, r := range downloaded {
+		if r.From <= to && r.To >= from {
+			done += min(r.To, to) - max(r.From, from) + 1
+		}
+	}
+	if done == to-from+1 {
+		log.Printf("slot range already downloaded. from=%d to=%d", from, to)
+		return nil
+	} else if done != 0 {
+		log.Printf("resuming download. from=%d to=%d downloaded=%d", from, to, done)
 	}
 
-	var blobSidecars []*ethpb.BlobSidecar
-	for {
-		isFirstChunk := len(blobSidecars) == 0
-		blobs, err := readChunkedBlobsSidecar(stream, encoding, isFirstChunk)
-		if errors.Is(err, io.EOF) {
-			break
+	for slot := from; slot <= to; slot++ {
+		if containsSlot(downloaded, slot) {
+			continue
 		}
-		if err != nil {
-			return nil, fmt.Errorf("%w: readChunkedBlobsSidecar", err)
+		blockID := strconv.FormatUint(slot, 10)
+		sidecars, err := source.BlobSidecars(ctx, blockID)
+		if errors.Is(err, errBeaconNotFound) {
+			log.Printf("skipping missed slot. slot=%d", slot)
+		} else if err != nil {
+			return fmt.Errorf("%w: unable to fetch blob sidecars of slot %d", err, slot)
+		} else if len(sidecars) != 0 {
+			// every sidecar must belong to the requested slot before any is stored, or the blobs of another slot would
+			// be recorded as this one's
+			if err := verifyBlobSidecars(blockID, sidecars); err != nil {
+				return err
+			}
+			stored := 0
+			for _, sidecar := range sidecars {
+				ok, err := store.Put(sidecar)
+				if err != nil {
+					return err
+				}
+				if ok {
+					stored++
+				}
+				if writer != nil {
+					if err := writer.Write(sidecar); err != nil {
+						return err
+					}
+				}
+			}
+			log.Printf("stored blobs. slot=%d blobs=%d new=%d", slot, len(sidecars), stored)
+		}
+		if err := store.SetDownloaded(slot); err != nil {
+			return fmt.Errorf("%w: unable to record downloaded slot %d", err, slot)
 		}
-		blobSidecars = append(blobSidecars, blobs)
 	}
-	return blobSidecars, nil
+	return nil
 }
 
-func readChunkedBlobsSidecar(stream libp2pcore.Stream, encoding encoder.NetworkEncoding, isFirstChunk bool) (*ethpb.BlobSidecar, error) {
+// verifyBlobSidecars checks every blob against its kzg commitment and proof, and that the commitment is included in
+// the block the sidecar claims to belong to. The result is logged for each sidecar. An error is returned if any of the
+// sidecars fails verification, or if the sidecars do not all belong to the same block. The block ID is the slot or
+// the block root the sidecars were requested for, and they must belong to that block.
+func verifyBlobSidecars(blockID string, sidecars []*BlobSidecar) error {
 	var (
-		code   uint8
-		errMsg string
-		err    error
+		failed    int
+		blockRoot common.Hash
+		slot      uint64
+		bySlot    = !strings.HasPrefix(blockID, "0x")
 	)
-	if isFirstChunk {
-		code, errMsg, err = sync.ReadStatusCode(stream, encoding)
-	} else {
-		sync.SetStreamReadDeadline(stream, time.Second*10)
-		code, errMsg, err = readStatusCodeNoDeadline(stream, encoding)
+	if bySlot {
+		var err error
+		if slot, err = strconv.ParseUint(blockID, 10, 64); err != nil {
+			return fmt.Errorf("invalid block id %s, expected a slot or block root", blockID)
+		}
 	}
-	if err != nil {
-		return nil, err
+	for i, sidecar := range sidecars {
+		if bySlot && sidecar.Slot() != slot {
+			return fmt.Errorf("blob sidecar %d belongs to slot %d, requested %d", sidecar.Index, sidecar.Slot(), slot)
+		}
+		root := sidecar.BlockRoot()
+		if i == 0 {
+			blockRoot = root
+		} else if root != blockRoot {
+			return fmt.Errorf("blob sidecars belong to different blocks: %v and %v", blockRoot, root)
+		}
+
+		versionedHash := kZGToVersionedHash(sidecar.KZGCommitment)
+		if err := kzg4844.VerifyBlobProof(sidecar.Blob, sidecar.KZGCommitment, sidecar.KZGProof); err != nil {
+			log.Printf("blob sidecar verification failed. slot=%d index=%d versionedHash=%v err=%v", sidecar.Slot(), sidecar.Index, versionedHash, err)
+			failed++
+			continue
+		}
+		if err := sidecar.VerifyInclusionProof(); err != nil {
+			log.Printf("blob sidecar verification failed. slot=%d index=%d versionedHash=%v err=%v", sidecar.Slot(), sidecar.Index, versionedHash, err)
+			failed++
+			continue
+		}
+		log.Printf("blob sidecar verified. slot=%d index=%d versionedHash=%v blockRoot=%v", sidecar.Slot(), sidecar.Index, versionedHash, root)
 	}
-	if code != 0 {
-		return nil, errors.New(errMsg)
+	if failed != 0 {
+		return fmt.Errorf("%d of %d blob sidecars failed verification", failed, len(sidecars))
 	}
-	// ignored: we assume we got the correct context
-	b := make([]byte, 4)
-	if _, err := stream.Read(b); err != nil {
-		return nil, err
+	if !bySlot && common.HexToHash(blockID) != blockRoot {
+		return fmt.Errorf("blob sidecars belong to block %v, requested %s", blockRoot, blockID)
 	}
-	sidecar := new(ethpb.BlobSidecar)
-	err = encoding.DecodeWithMaxLength(stream, sidecar)
-	return sidecar, err
+	return nil
 }
 
-func readStatusCodeNoDeadline(stream libp2pcore.Stream, encoding encoder.NetworkEncoding) (uint8, string, error) {
-	b := make([]byte, 1)
-	_, err := stream.Read(b)
-	if err != nil {
-		return 0, "", err
+// blobWriter decodes downloaded blobs with its codec and writes out their payloads, or passes them to the blob decoder
+// if it is set
+type blobWriter struct {
+	outputDir string
+	codec     BlobCodec
+	decoder   BlobDecoder
+}
+
+// Write writes the decoded blob payload to stdout, or to a file named after the slot and blob index if outputDir is set
+func (w *blobWriter) Write(sidecar *BlobSidecar) error {
+	data, err := w.codec.Decode(sidecar.Blob[:])
+	if err != nil && w.decoder != nil {
+		log.Printf("skipping blob. slot=%d index=%d err=%v", sidecar.Slot(), sidecar.Index, err)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("%w: unable to decode blob %d of slot %d", err, sidecar.Index, sidecar.Slot())
+	}
+	if w.decoder != nil {
+		if err := w.decoder.AddBlob(data); err != nil {
+			log.Printf("skipping undecodable blob. slot=%d index=%d err=%v", sidecar.Slot(), sidecar.Index, err)
+		}
+		return nil
 	}
-	if b[0] == responseCodeSuccess {
-		return 0, "", nil
+	if w.outputDir == "" {
+		_, err := os.Stdout.Write(data)
+		return err
 	}
-	msg := &p2ptypes.ErrorMessage{}
-	if err := encoding.DecodeWithMaxLength(stream, msg); err != nil {
-		return 0, "", err
+	name := filepath.Join(w.outputDir, fmt.Sprintf("%d-%d.bin", sidecar.Slot(), sidecar.Index))
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		return fmt.Errorf("%w: unable to write blob", err)
 	}
-	return b[0], string(*msg), nil
+	log.Printf("wrote blob. slot=%d index=%d file=%s", sidecar.Slot(), sidecar.Index, name)
+	return nil
 }
 
-var responseCodeSuccess = byte(0x00)
-*/
\ No newline at end of file
+// Close flushes the blob decoder
+func (w *blobWriter) Close() error {
+	if w.decoder != nil {
+		return w.decoder.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/urfave/cli"
+)
+
+var (
+	testSidecarsOnce sync.Once
+	testSidecars     []*BlobSidecar
+	testSidecarsErr  error
+)
+
+// testBlobSidecars returns the valid sidecars of a block at slot holding two blobs
+func testBlobSidecars(t *testing.T, slot uint64) []*BlobSidecar {
+	testSidecarsOnce.Do(func() {
+		data := make([]byte, params.BlobTxFieldElementsPerBlob*31+1)
+		for i := range data {
+			data[i] = byte(i % 64)
+		}
+		blobs, commitments, proofs, _, err := EncodeBlobs(data, legacyCodec{})
+		if err != nil {
+			testSidecarsErr = err
+			return
+		}
+		commitmentLeaves := make([][32]byte, maxBlobCommitmentsPerBlock)
+		for i := range commitments {
+			commitmentLeaves[i] = commitmentRoot(commitments[i][:])
+		}
+		var length [32]byte
+		binary.LittleEndian.PutUint64(length[:], uint64(len(commitments)))
+		listRoot, _ := merkleBranch(commitmentLeaves, 0)
+		bodyLeaves := make([][32]byte, 16)
+		bodyLeaves[blobKzgCommitmentsIndex] = hashPair(listRoot, length)
+		bodyRoot, bodyBranch := merkleBranch(bodyLeaves, blobKzgCommitmentsIndex)
+
+		for i := range blobs {
+			_, listBranch := merkleBranch(commitmentLeaves, i)
+			sidecar := &BlobSidecar{
+				Index:                       uint64(i),
+				Blob:                        blobs[i],
+				KZGCommitment:               commitments[i],
+				KZGProof:                    proofs[i],
+				KZGCommitmentInclusionProof: append(append(listBranch, length), bodyBranch...),
+			}
+			sidecar.SignedBlockHeader.Message.ProposerIndex = 3
+			sidecar.SignedBlockHeader.Message.BodyRoot = bodyRoot
+			sidecar.SignedBlockHeader.Signature = make([]byte, 96)
+			testSidecars = append(testSidecars, sidecar)
+		}
+	})
+	if testSidecarsErr != nil {
+		t.Fatal(testSidecarsErr)
+	}
+	sidecars := make([]*BlobSidecar, len(testSidecars))
+	for i := range testSidecars {
+		sidecar := *testSidecars[i]
+		sidecar.SignedBlockHeader.Message.Slot = slot
+		sidecars[i] = &sidecar
+	}
+	return sidecars
+}
+
+// testBlobSidecarSource serves the test sidecars for every slot but the missed ones, and records the fetched slots.
+// The sidecars served for a slot belong to the slot shift slots later.
+type testBlobSidecarSource struct {
+	t       *testing.T
+	missed  map[uint64]bool
+	failAt  uint64
+	shift   uint64
+	fetched []uint64
+}
+
+func (s *testBlobSidecarSource) BlobSidecars(ctx context.Context, blockID string, indices ...uint64) ([]*BlobSidecar, error) {
+	slot, err := strconv.ParseUint(blockID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	s.fetched = append(s.fetched, slot)
+	if slot == s.failAt {
+		s.failAt = 0
+		return nil, errors.New("connection reset")
+	}
+	if s.missed[slot] {
+		return nil, errBeaconNotFound
+	}
+	return testBlobSidecars(s.t, slot+s.shift), nil
+}
+
+func (s *testBlobSidecarSource) BlockRoot(ctx context.Context, blockID string) (common.Hash, error) {
+	return common.Hash{}, fmt.Errorf("unsupported block id %s", blockID)
+}
+
+func slots(from, to uint64) []uint64 {
+	var slots []uint64
+	for slot := from; slot <= to; slot++ {
+		slots = append(slots, slot)
+	}
+	return slots
+}
+
+func TestDownloadSlotRange(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		from, to uint64
+		failAt   uint64
+		fetched  []uint64
+	}{
+		{name: "interrupted", from: 100, to: 105, failAt: 103, fetched: slots(100, 103)},
+		{name: "resume", from: 100, to: 105, fetched: slots(103, 105)},
+		{name: "earlier range", from: 10, to: 20, fetched: slots(10, 20)},
+		{name: "already downloaded", from: 100, to: 105},
+		{name: "overlapping range", from: 5, to: 25, fetched: append(slots(5, 9), slots(21, 25)...)},
+		{name: "later range", from: 104, to: 108, fetched: slots(106, 108)},
+	}
+	for _, tt := range tests {
+		source := &testBlobSidecarSource{t: t, missed: map[uint64]bool{12: true}, failAt: tt.failAt}
+		err := downloadSlotRange(ctx, source, dir, nil, tt.from, tt.to)
+		if (err != nil) != (tt.failAt != 0) {
+			t.Fatalf("%s: unexpected error %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(source.fetched, tt.fetched) {
+			t.Fatalf("%s: expected to fetch slots %v, fetched %v", tt.name, tt.fetched, source.fetched)
+		}
+	}
+
+	store, err := openBlobStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	downloaded, err := store.DownloadedSlots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []slotRange{{5, 25}, {100, 108}}; !reflect.DeepEqual(downloaded, expected) {
+		t.Fatalf("expected downloaded slots %v, got %v", expected, downloaded)
+	}
+	for _, sidecar := range testBlobSidecars(t, 0) {
+		if _, err := store.Get(kZGToVersionedHash(sidecar.KZGCommitment)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := downloadSlotRange(ctx, &testBlobSidecarSource{t: t}, dir, nil, 2, 1); err == nil {
+		t.Fatal("expected invalid slot range")
+	}
+}
+
+func TestDownloadSlotRangeInvalidSidecars(t *testing.T) {
+	tests := []struct {
+		name   string
+		source blobSidecarSource
+	}{
+		{name: "invalid sidecars", source: &invalidBlobSidecarSource{}},
+		{name: "sidecars of another slot", source: &testBlobSidecarSource{t: t, shift: 1}},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		if err := downloadSlotRange(context.Background(), tt.source, dir, nil, 1, 2); err == nil {
+			t.Fatalf("%s: expected the sidecars to be rejected", tt.name)
+		}
+		store, err := openBlobStore(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if downloaded, err := store.DownloadedSlots(); err != nil || len(downloaded) != 0 {
+			t.Fatalf("%s: unexpected downloaded slots %v: %v", tt.name, downloaded, err)
+		}
+		if len(store.indexed) != 0 {
+			t.Fatalf("%s: unexpected %d stored blobs", tt.name, len(store.indexed))
+		}
+		store.Close()
+	}
+}
+
+// invalidBlobSidecarSource serves sidecars whose commitments aren't included in their block
+type invalidBlobSidecarSource struct{}
+
+func (s *invalidBlobSidecarSource) BlobSidecars(ctx context.Context, blockID string, indices ...uint64) ([]*BlobSidecar, error) {
+	slot, _ := strconv.ParseUint(blockID, 10, 64)
+	sidecar := &BlobSidecar{SignedBlockHeader: SignedBeaconBlockHeader{Message: BeaconBlockHeader{Slot: slot, BodyRoot: common.Hash{1}}}}
+	return []*BlobSidecar{sidecar}, nil
+}
+
+func (s *invalidBlobSidecarSource) BlockRoot(ctx context.Context, blockID string) (common.Hash, error) {
+	return common.Hash{}, fmt.Errorf("unsupported block id %s", blockID)
+}
+
+func TestVerifyBlobSidecars(t *testing.T) {
+	blockRoot := testBlobSidecars(t, 9)[0].BlockRoot().Hex()
+	tests := []struct {
+		name    string
+		blockID string
+		tamper  func(sidecars []*BlobSidecar)
+		valid   bool
+	}{
+		{name: "valid", blockID: "9", valid: true},
+		{name: "requested block root", blockID: blockRoot, valid: true},
+		{name: "other block root", blockID: common.Hash{1}.Hex()},
+		{name: "other slot", blockID: "8"},
+		{name: "named block", blockID: "head"},
+		{name: "bad proof", blockID: "9", tamper: func(sidecars []*BlobSidecar) {
+			sidecars[0].KZGProof, sidecars[1].KZGProof = sidecars[1].KZGProof, sidecars[0].KZGProof
+		}},
+		{name: "bad commitment", blockID: "9", tamper: func(sidecars []*BlobSidecar) {
+			sidecars[1].KZGCommitment = sidecars[0].KZGCommitment
+		}},
+		{name: "bad blob", blockID: "9", tamper: func(sidecars []*BlobSidecar) {
+			sidecars[1].Blob[0] ^= 1
+		}},
+		{name: "bad inclusion proof", blockID: "9", tamper: func(sidecars []*BlobSidecar) {
+			sidecars[0].Index = 1
+		}},
+		{name: "different blocks", blockID: "9", tamper: func(sidecars []*BlobSidecar) {
+			sidecars[1].SignedBlockHeader.Message.Slot++
+		}},
+	}
+	for _, tt := range tests {
+		sidecars := testBlobSidecars(t, 9)
+		if tt.tamper != nil {
+			tt.tamper(sidecars)
+		}
+		if err := verifyBlobSidecars(tt.blockID, sidecars); (err == nil) != tt.valid {
+			t.Fatalf("%s: unexpected verification result %v", tt.name, err)
+		}
+	}
+}
+
+func TestMatchTxBlobSidecars(t *testing.T) {
+	sidecars := testBlobSidecars(t, 4)
+	hashes := []common.Hash{kZGToVersionedHash(sidecars[0].KZGCommitment), kZGToVersionedHash(sidecars[1].KZGCommitment)}
+	tests := []struct {
+		name     string
+		hashes   []common.Hash
+		expected []*BlobSidecar
+	}{
+		{name: "all blobs", hashes: hashes, expected: sidecars},
+		{name: "one blob", hashes: hashes[1:], expected: sidecars[1:]},
+		{name: "reordered blobs", hashes: []common.Hash{hashes[1], hashes[0]}, expected: []*BlobSidecar{sidecars[1], sidecars[0]}},
+		{name: "missing blob", hashes: []common.Hash{hashes[0], {1}}},
+	}
+	for _, tt := range tests {
+		tx := types.NewTx(&types.BlobTx{BlobHashes: tt.hashes})
+		matched, err := matchTxBlobSidecars(tx, 4, sidecars)
+		if (err != nil) != (tt.expected == nil) {
+			t.Fatalf("%s: unexpected error %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(matched, tt.expected) {
+			t.Fatalf("%s: unexpected sidecars", tt.name)
+		}
+	}
+}
+
+// flushErrorDecoder counts the added blobs and fails to flush
+type flushErrorDecoder struct {
+	blobs int
+}
+
+func (d *flushErrorDecoder) Codec() BlobCodec {
+	return legacyCodec{}
+}
+
+func (d *flushErrorDecoder) AddBlob(data []byte) error {
+	d.blobs++
+	return nil
+}
+
+func (d *flushErrorDecoder) Flush() error {
+	return errors.New("flush error")
+}
+
+func TestDownloadAppFlushError(t *testing.T) {
+	sidecars := testBlobSidecars(t, 5)
+	srv := newTestBeaconServer(t, map[string]interface{}{
+		"/eth/v1/beacon/blob_sidecars/5": blobSidecarsResponse(sidecars...),
+	})
+	decoder := new(flushErrorDecoder)
+	blobDecoders["flush-error"] = func(out io.Writer) BlobDecoder { return decoder }
+	defer delete(blobDecoders, "flush-error")
+
+	set := flag.NewFlagSet("download", flag.ContinueOnError)
+	for _, f := range DownloadFlags {
+		f.Apply(set)
+	}
+	if err := set.Parse([]string{"--beacon-url", srv.URL, "--block-id", "5", "--format", "flush-error"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := DownloadApp(cli.NewContext(cli.NewApp(), set, nil)); err == nil || err.Error() != "flush error" {
+		t.Fatalf("expected the flush error, got %v", err)
+	}
+	if decoder.blobs != len(sidecars) {
+		t.Fatalf("expected %d decoded blobs, got %d", len(sidecars), decoder.blobs)
+	}
+}
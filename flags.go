@@ -64,16 +64,97 @@ var (
 		Usage: "calldata of the transaction",
 		Value: "0x",
 	}
+	TxCodecFlag = cli.StringFlag{
+		Name:  "codec",
+		Usage: "Codec used to encode the blob file into blobs (legacy, lossless, dense, op, padded or zksync)",
+		Value: "legacy",
+	}
+	TxRawBlobFlag = cli.BoolFlag{
+		Name:  "raw-blob",
+		Usage: "The blob file holds already encoded 131072 byte blobs, which are validated instead of encoded with --codec",
+	}
+	TxSidecarVersionFlag = cli.Uint64Flag{
+		Name:  "sidecar-version",
+		Usage: "Version of the blob sidecar: 0 for a blob proof per blob (EIP-4844), 1 for cell proofs (EIP-7594), as expected by Osaka nodes",
+		Value: sidecarVersion0,
+	}
+	TxOfflineFlag = cli.BoolFlag{
+		Name:  "offline",
+		Usage: "Sign the transaction without connecting to a node and write it to --out instead of sending it. Requires --nonce, --gas-price, --priority-gas-price, --max-fee-per-blob-gas and --chain-id",
+	}
+	TxOutFlag = cli.StringFlag{
+		Name:  "out",
+		Usage: "File to write the network encoded signed transaction to with --offline. A JSON summary is written next to it with a .json extension",
+	}
 
 	DownloadBeaconP2PAddr = cli.StringFlag{
 		Name:  "beacon-p2p-addr",
-		Usage: "P2P multiaddr of the beacon node",
+		Usage: "P2P multiaddr of the beacon node. If set, blobs are downloaded over libp2p instead of the REST API, which is only used for the fork schedule",
 		Value: "/ip4/127.0.0.1/tcp/13000",
 	}
+	DownloadForkDigestFlag = cli.StringFlag{
+		Name:  "fork-digest",
+		Usage: "Fork digest to use in the libp2p status handshake if the peer doesn't send its status first",
+	}
+	DownloadBeaconURLFlag = cli.StringFlag{
+		Name:  "beacon-url",
+		Usage: "Address of the beacon node REST API",
+		Value: "http://127.0.0.1:5052",
+	}
+	DownloadBlockIDFlag = cli.StringFlag{
+		Name:  "block-id",
+		Usage: "Block to download blobs from (slot, block root, head or finalized)",
+		Value: "head",
+	}
 	DownloadSlotFlag = cli.Int64Flag{
-		Name:     "slot",
-		Usage:    "Slot to download blob from",
-		Required: true,
+		Name:  "slot",
+		Usage: "Slot to download blob from. Overrides --block-id",
+	}
+	DownloadTxHashFlag = cli.StringFlag{
+		Name:  "tx-hash",
+		Usage: "Download the blobs of this transaction. Overrides --block-id and --slot",
+	}
+	DownloadRPCURLFlag = cli.StringFlag{
+		Name:  "rpc-url",
+		Usage: "Address of execution node JSON-RPC endpoint, used to look up --tx-hash and --from-address",
+		Value: "http://127.0.0.1:8545",
+	}
+	DownloadFromSlotFlag = cli.Uint64Flag{
+		Name:  "from-slot",
+		Usage: "First slot of a range of slots to download blobs from into the blob store at --output-dir",
+	}
+	DownloadToSlotFlag = cli.Uint64Flag{
+		Name:  "to-slot",
+		Usage: "Last slot (inclusive) of the range started by --from-slot",
+	}
+	DownloadFollowFlag = cli.BoolFlag{
+		Name:  "follow",
+		Usage: "Keep running and download new blobs as they appear on the beacon node event stream",
+	}
+	DownloadFromAddressFlag = cli.StringFlag{
+		Name:  "from-address",
+		Usage: "Only download blobs of transactions sent by this address (--follow only). Requires --rpc-url",
+	}
+	DownloadVersionedHashPrefixFlag = cli.StringFlag{
+		Name:  "versioned-hash-prefix",
+		Usage: "Only download blobs whose versioned hash starts with this hex prefix (--follow only)",
+	}
+	DownloadOutputDirFlag = cli.StringFlag{
+		Name:  "output-dir",
+		Usage: "Directory to write the blobs to. Blobs are written to stdout if unset",
+	}
+	DownloadFormatFlag = cli.StringFlag{
+		Name:  "format",
+		Usage: "Decode the blob payloads as rollup batches and print them as JSON instead of writing them out (op-frames, arbitrum, taiko, scroll or zksync)",
+	}
+	DownloadDecodeFlag = cli.StringFlag{
+		Name:  "decode",
+		Usage: "Alias of --format",
+	}
+	DownloadCodecFlag = cli.StringFlag{
+		Name:  "codec",
+		Usage: "Codec used to decode the downloaded blobs (legacy, lossless, dense, op, padded or zksync)",
+		Value: "legacy",
 	}
 
 	ProofBlobFileFlag = cli.StringFlag{
@@ -82,15 +163,168 @@ var (
 		Required: true,
 	}
 	ProofBlobIndexFlag = cli.StringFlag{
-		Name:     "blob-index",
-		Usage:    "Blob index",
-		Required: true,
+		Name:  "blob-index",
+		Usage: "Blob index",
 	}
 	ProofInputPointFlag = cli.StringFlag{
-		Name:     "input-point",
-		Usage:    "Input point of the proof",
+		Name:  "input-point",
+		Usage: "Input point of the proof",
+	}
+	ProofPointsFileFlag = cli.StringFlag{
+		Name:  "points-file",
+		Usage: "File of input points, one hex encoded point per line. The proofs are printed as a JSON array",
+	}
+	ProofAllBlobsFlag = cli.BoolFlag{
+		Name:  "all-blobs",
+		Usage: "Open every blob of the blob file instead of the one at --blob-index. The proofs are printed as a JSON array",
+	}
+	ProofChallengeFlag = cli.BoolFlag{
+		Name:  "challenge",
+		Usage: "Open the blobs at their Fiat-Shamir challenge, the point the blob proof evaluates them at, instead of an input point",
+	}
+	ProofEvalOnlyFlag = cli.BoolFlag{
+		Name:  "eval-only",
+		Usage: "Only evaluate the blobs at the input points, without the KZG backend, instead of computing proofs",
+	}
+	ProofCodecFlag = cli.StringFlag{
+		Name:  "codec",
+		Usage: "Codec used to encode the blob file into blobs (legacy, lossless, dense, op, padded or zksync)",
+		Value: "legacy",
+	}
+
+	InspectBlobFileFlag = cli.StringFlag{
+		Name:  "blob-file",
+		Usage: "File holding a single blob, either raw or hex encoded",
+	}
+	InspectVersionedHashFlag = cli.StringFlag{
+		Name:  "versioned-hash",
+		Usage: "Versioned hash of a blob in the blob store at --store-dir",
+	}
+	InspectStoreDirFlag = cli.StringFlag{
+		Name:  "store-dir",
+		Usage: "Directory of a blob store written by download --from-slot, used to look up --versioned-hash",
+	}
+
+	VerifyBlobFileFlag = cli.StringFlag{
+		Name:  "blob-file",
+		Usage: "File holding the blob, either raw or hex encoded",
+	}
+	VerifyBlobFlag = cli.StringFlag{
+		Name:  "blob",
+		Usage: "Hex encoded blob",
+	}
+	VerifyCommitmentFlag = cli.StringFlag{
+		Name:  "commitment",
+		Usage: "KZG commitment of the blob. Computed from the blob if unset",
+	}
+	VerifyProofFlag = cli.StringFlag{
+		Name:  "proof",
+		Usage: "KZG blob proof to verify against the commitment",
+	}
+	VerifyVersionedHashFlag = cli.StringFlag{
+		Name:  "versioned-hash",
+		Usage: "Versioned hash to check against the commitment",
+	}
+	VerifyPointEvalInputFlag = cli.StringFlag{
+		Name:  "point-eval-input",
+		Usage: "Hex encoded 192 byte point evaluation precompile input to verify instead of a blob",
+	}
+
+	PolynomialBlobFileFlag = cli.StringFlag{
+		Name:  "blob-file",
+		Usage: "File holding a blob, either raw or hex encoded, to convert to coefficient form",
+	}
+	PolynomialCoefficientsFileFlag = cli.StringFlag{
+		Name:  "coefficients-file",
+		Usage: "File holding 4096 big endian coefficients, as a JSON array of hex strings or raw or hex encoded bytes, to convert to a blob",
+	}
+	PolynomialOutputFormatFlag = cli.StringFlag{
+		Name:  "output-format",
+		Usage: "json prints both forms, hex only prints the converted form",
+		Value: "json",
+	}
+
+	CellsBlobFileFlag = cli.StringFlag{
+		Name:  "blob-file",
+		Usage: "Blob file data to extend to cells",
+	}
+	CellsCodecFlag = cli.StringFlag{
+		Name:  "codec",
+		Usage: "Codec used to encode the blob file into blobs (legacy, lossless, dense, op, padded or zksync)",
+		Value: "legacy",
+	}
+	CellsRawBlobFlag = cli.BoolFlag{
+		Name:  "raw-blob",
+		Usage: "The blob file holds already encoded 131072 byte blobs, which are validated instead of encoded with --codec",
+	}
+	CellsFileFlag = cli.StringFlag{
+		Name:  "cells-file",
+		Usage: "JSON file of cells and proofs, as printed by this command, to verify",
+	}
+	CellsRecoverFlag = cli.BoolFlag{
+		Name:  "recover",
+		Usage: "Recover all cells of each blob of the cells file from at least half of them",
+	}
+	CellsCellIndicesFlag = cli.StringFlag{
+		Name:  "cell-indices",
+		Usage: "Comma separated indices of the cells to print. All cells are printed by default",
+	}
+
+	RecoverCellsFileFlag = cli.StringSliceFlag{
+		Name:  "cells-file",
+		Usage: "File of cells, either the JSON output of the cells command or data column sidecars as JSON or SSZ. May be repeated to merge cells from several sources",
+	}
+	RecoverBeaconURLFlag = cli.StringFlag{
+		Name:  "beacon-url",
+		Usage: "Address of a beacon node REST API to download data column sidecars from",
+	}
+	RecoverBlockIDFlag = cli.StringFlag{
+		Name:  "block-id",
+		Usage: "Block to download data column sidecars from (slot, block root, head or finalized)",
+		Value: "head",
+	}
+	RecoverColumnIndicesFlag = cli.StringFlag{
+		Name:  "column-indices",
+		Usage: "Comma separated indices of the columns to download from --beacon-url. All columns are downloaded by default",
+	}
+	RecoverCodecFlag = cli.StringFlag{
+		Name:  "codec",
+		Usage: "Codec used to decode the recovered blobs (legacy, lossless, dense, op, padded or zksync)",
+		Value: "legacy",
+	}
+	RecoverOutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "File to write the decoded payload of the recovered blobs to. The payload is written to stdout if unset",
+	}
+
+	SendRawTxFileFlag = cli.StringFlag{
+		Name:     "tx-file",
+		Usage:    "File holding a signed blob transaction, raw or hex encoded, as written by tx --offline",
 		Required: true,
 	}
+	SendRawRPCURLFlag = cli.StringFlag{
+		Name:  "rpc-url",
+		Usage: "Address of execution node JSON-RPC endpoint",
+		Value: "http://127.0.0.1:8545",
+	}
+	SendRawBlobFileFlag = cli.StringFlag{
+		Name:  "blob-file",
+		Usage: "Blob file data to rebuild the sidecar of a transaction signed without one. Its blobs must match the versioned hashes of the transaction",
+	}
+	SendRawCodecFlag = cli.StringFlag{
+		Name:  "codec",
+		Usage: "Codec used to encode the blob file into blobs (legacy, lossless, dense, op, padded or zksync)",
+		Value: "legacy",
+	}
+	SendRawRawBlobFlag = cli.BoolFlag{
+		Name:  "raw-blob",
+		Usage: "The blob file holds already encoded 131072 byte blobs, which are validated instead of encoded with --codec",
+	}
+	SendRawSidecarVersionFlag = cli.Uint64Flag{
+		Name:  "sidecar-version",
+		Usage: "Version of the sidecar rebuilt from --blob-file: 0 for a blob proof per blob (EIP-4844), 1 for cell proofs (EIP-7594)",
+		Value: sidecarVersion0,
+	}
 )
 
 var TxFlags = []cli.Flag{
@@ -106,15 +340,87 @@ var TxFlags = []cli.Flag{
 	TxMaxFeePerBlobGas,
 	TxChainID,
 	TxCalldata,
+	TxCodecFlag,
+	TxRawBlobFlag,
+	TxSidecarVersionFlag,
+	TxOfflineFlag,
+	TxOutFlag,
 }
 
 var DownloadFlags = []cli.Flag{
+	DownloadBeaconURLFlag,
 	DownloadBeaconP2PAddr,
+	DownloadForkDigestFlag,
+	DownloadBlockIDFlag,
 	DownloadSlotFlag,
+	DownloadTxHashFlag,
+	DownloadRPCURLFlag,
+	DownloadFromSlotFlag,
+	DownloadToSlotFlag,
+	DownloadFollowFlag,
+	DownloadFromAddressFlag,
+	DownloadVersionedHashPrefixFlag,
+	DownloadOutputDirFlag,
+	DownloadCodecFlag,
+	DownloadFormatFlag,
+	DownloadDecodeFlag,
 }
 
 var ProofFlags = []cli.Flag{
 	ProofBlobFileFlag,
 	ProofBlobIndexFlag,
 	ProofInputPointFlag,
+	ProofPointsFileFlag,
+	ProofAllBlobsFlag,
+	ProofChallengeFlag,
+	ProofEvalOnlyFlag,
+	ProofCodecFlag,
+}
+
+var InspectFlags = []cli.Flag{
+	InspectBlobFileFlag,
+	InspectVersionedHashFlag,
+	InspectStoreDirFlag,
+}
+
+var VerifyFlags = []cli.Flag{
+	VerifyBlobFileFlag,
+	VerifyBlobFlag,
+	VerifyCommitmentFlag,
+	VerifyProofFlag,
+	VerifyVersionedHashFlag,
+	VerifyPointEvalInputFlag,
+}
+
+var PolynomialFlags = []cli.Flag{
+	PolynomialBlobFileFlag,
+	PolynomialCoefficientsFileFlag,
+	PolynomialOutputFormatFlag,
+}
+
+var CellsFlags = []cli.Flag{
+	CellsBlobFileFlag,
+	CellsCodecFlag,
+	CellsRawBlobFlag,
+	CellsFileFlag,
+	CellsRecoverFlag,
+	CellsCellIndicesFlag,
+}
+
+var RecoverFlags = []cli.Flag{
+	RecoverCellsFileFlag,
+	RecoverBeaconURLFlag,
+	RecoverBlockIDFlag,
+	RecoverColumnIndicesFlag,
+	RecoverCodecFlag,
+	RecoverOutputFlag,
+}
+
+var SendRawFlags = []cli.Flag{
+	SendRawTxFileFlag,
+	SendRawRPCURLFlag,
+	SendRawBlobFileFlag,
+	SendRawCodecFlag,
+	SendRawRawBlobFlag,
+	SendRawSidecarVersionFlag,
 }
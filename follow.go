@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// followSeenSlots is how long the versioned hashes of processed blobs are remembered to avoid processing them twice
+const followSeenSlots = 64
+
+type blobSidecarEvent struct {
+	BlockRoot     common.Hash `json:"block_root"`
+	Index         uint64      `json:"index,string"`
+	Slot          uint64      `json:"slot,string"`
+	VersionedHash common.Hash `json:"versioned_hash"`
+}
+
+type headEvent struct {
+	Slot  uint64      `json:"slot,string"`
+	Block common.Hash `json:"block"`
+}
+
+// blobFollower streams new blobs from the beacon node event stream, optionally filtered by the sender of the blob
+// transaction or by a versioned hash prefix
+type blobFollower struct {
+	client    *beaconClient
+	ethClient *ethclient.Client
+	writer    *blobWriter
+
+	from *common.Address
+	// prefix is the versioned hash prefix in lowercase hex digits, which may be odd in number
+	prefix string
+
+	seen map[common.Hash]uint64
+}
+
+func (f *blobFollower) run(ctx context.Context) error {
+	topics := []string{"blob_sidecar", "head"}
+	for {
+		// errors from the handler are fatal, the stream is reconnected on any other error
+		var handlerErr error
+		err := f.client.Events(ctx, topics, func(topic string, data []byte) error {
+			handlerErr = f.handleEvent(ctx, topic, data)
+			return handlerErr
+		})
+		if handlerErr != nil {
+			return handlerErr
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("event stream disconnected, reconnecting. err=%v", err)
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (f *blobFollower) handleEvent(ctx context.Context, topic string, data []byte) error {
+	switch topic {
+	case "blob_sidecar":
+		var event blobSidecarEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("%w: invalid blob_sidecar event", err)
+		}
+		if _, ok := f.seen[event.VersionedHash]; ok || !f.matchesPrefix(event.VersionedHash) {
+			return nil
+		}
+		// the block may not have been imported yet, in which case its blobs are picked up with the head event
+		sidecars, err := f.client.BlobSidecars(ctx, event.BlockRoot.Hex(), event.Index)
+		if errors.Is(err, errBeaconNotFound) {
+			return nil
+		} else if err != nil {
+			log.Printf("unable to fetch blob sidecar. slot=%d index=%d err=%v", event.Slot, event.Index, err)
+			return nil
+		}
+		return f.process(ctx, event.BlockRoot, sidecars)
+	case "head":
+		var event headEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("%w: invalid head event", err)
+		}
+		for versionedHash, slot := range f.seen {
+			if slot+followSeenSlots < event.Slot {
+				delete(f.seen, versionedHash)
+			}
+		}
+		sidecars, err := f.client.BlobSidecars(ctx, event.Block.Hex())
+		if err != nil {
+			log.Printf("unable to fetch blob sidecars. slot=%d err=%v", event.Slot, err)
+			return nil
+		}
+		return f.process(ctx, event.Block, sidecars)
+	}
+	return nil
+}
+
+// process verifies and writes out the sidecars of a block that pass the filters and haven't been processed yet
+func (f *blobFollower) process(ctx context.Context, blockRoot common.Hash, sidecars []*BlobSidecar) error {
+	var fresh []*BlobSidecar
+	for _, sidecar := range sidecars {
+		versionedHash := kZGToVersionedHash(sidecar.KZGCommitment)
+		if _, ok := f.seen[versionedHash]; !ok && f.matchesPrefix(versionedHash) {
+			fresh = append(fresh, sidecar)
+		}
+	}
+	if len(fresh) == 0 {
+		return nil
+	}
+	if err := verifyBlobSidecars(blockRoot.Hex(), fresh); err != nil {
+		return err
+	}
+
+	var senders map[common.Hash]common.Address
+	if f.from != nil {
+		var err error
+		if senders, err = f.blobSenders(ctx, blockRoot); err != nil {
+			return err
+		}
+	}
+	for _, sidecar := range fresh {
+		versionedHash := kZGToVersionedHash(sidecar.KZGCommitment)
+		f.seen[versionedHash] = sidecar.Slot()
+		if f.from != nil && senders[versionedHash] != *f.from {
+			continue
+		}
+		if err := f.writer.Write(sidecar); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *blobFollower) matchesPrefix(versionedHash common.Hash) bool {
+	return strings.HasPrefix(hex.EncodeToString(versionedHash[:]), f.prefix)
+}
+
+// blobSenders maps the versioned hashes of the blob transactions in a block to their senders
+func (f *blobFollower) blobSenders(ctx context.Context, blockRoot common.Hash) (map[common.Hash]common.Address, error) {
+	blockHash, err := f.client.ExecutionBlockHash(ctx, blockRoot.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get execution payload of block %v", err, blockRoot)
+	}
+	block, err := f.ethClient.BlockByHash(ctx, blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get execution block %v", err, blockHash)
+	}
+	senders := make(map[common.Hash]common.Address)
+	for _, tx := range block.Transactions() {
+		if tx.Type() != types.BlobTxType {
+			continue
+		}
+		sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to recover sender of %v", err, tx.Hash())
+		}
+		for _, versionedHash := range tx.BlobHashes() {
+			senders[versionedHash] = sender
+		}
+	}
+	return senders, nil
+}
+
+func newBlobFollower(ctx context.Context, client *beaconClient, rpcURL string, writer *blobWriter, from, prefix string) (*blobFollower, error) {
+	f := &blobFollower{
+		client: client,
+		writer: writer,
+		seen:   make(map[common.Hash]uint64),
+	}
+	if prefix != "" {
+		p := strings.TrimPrefix(strings.ToLower(prefix), "0x")
+		// an odd number of digits is padded to decode it
+		if _, err := hex.DecodeString(p + strings.Repeat("0", len(p)%2)); err != nil || len(p) > 2*common.HashLength {
+			return nil, fmt.Errorf("invalid versioned hash prefix %s", prefix)
+		}
+		f.prefix = p
+	}
+	if from != "" {
+		if !common.IsHexAddress(from) {
+			return nil, fmt.Errorf("invalid sender address %s", from)
+		}
+		addr := common.HexToAddress(from)
+		f.from = &addr
+		ethClient, err := ethclient.DialContext(ctx, rpcURL)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to connect to the Ethereum client", err)
+		}
+		f.ethClient = ethClient
+	}
+	return f, nil
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBeaconEvents(t *testing.T) {
+	stream := ": keepalive\n" +
+		"event: head\ndata: {\"slot\":\"1\"}\n\n" +
+		"event: blob_sidecar\r\ndata: {\"index\":\r\ndata: \"2\"}\r\n\r\n" +
+		"event: head\n\n" +
+		"data: {\"slot\":\"3\"}\n\n" +
+		"event: head\ndata: {\"slot\":\"4\"}\n\n"
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		query = r.URL.RawQuery
+		io.WriteString(w, stream)
+	}))
+	defer srv.Close()
+	client := newBeaconClient(srv.URL)
+
+	type event struct {
+		topic, data string
+	}
+	var events []event
+	err := client.Events(context.Background(), []string{"head", "blob_sidecar"}, func(topic string, data []byte) error {
+		events = append(events, event{topic, string(data)})
+		return nil
+	})
+	if !errors.Is(err, io.EOF) {
+		t.Fatalf("expected the stream to end, got %v", err)
+	}
+	if query != "topics=head,blob_sidecar" {
+		t.Fatalf("unexpected query %s", query)
+	}
+	expected := []event{{"head", `{"slot":"1"}`}, {"blob_sidecar", `{"index":"2"}`}, {"head", `{"slot":"4"}`}}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("expected events %v, got %v", expected, events)
+	}
+
+	// errors of the handler end the stream
+	handlerErr := errors.New("handler error")
+	events = nil
+	err = client.Events(context.Background(), []string{"head"}, func(topic string, data []byte) error {
+		events = append(events, event{topic, string(data)})
+		return handlerErr
+	})
+	if err != handlerErr || len(events) != 1 {
+		t.Fatalf("expected the handler error after one event, got %v after %d", err, len(events))
+	}
+}
+
+// newTestRPCServer serves eth_getBlockByHash with the JSON encoding of the block
+func newTestRPCServer(t *testing.T, block map[string]interface{}) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "eth_getBlockByHash" {
+			http.Error(w, fmt.Sprintf("unexpected request %s", req.Method), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": block})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// blockJSON returns the execution API encoding of a block holding the transactions
+func blockJSON(t *testing.T, txs ...*types.Transaction) (common.Hash, map[string]interface{}) {
+	header := &types.Header{
+		Difficulty: new(big.Int),
+		Number:     big.NewInt(1),
+		UncleHash:  types.EmptyUncleHash,
+		TxHash:     common.Hash{1},
+	}
+	enc, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var block map[string]interface{}
+	if err := json.Unmarshal(enc, &block); err != nil {
+		t.Fatal(err)
+	}
+	var encodedTxs []interface{}
+	for _, tx := range txs {
+		enc, err := json.Marshal(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var encoded map[string]interface{}
+		if err := json.Unmarshal(enc, &encoded); err != nil {
+			t.Fatal(err)
+		}
+		encoded["blockHash"] = header.Hash()
+		encoded["blockNumber"] = hexutil.EncodeBig(header.Number)
+		encodedTxs = append(encodedTxs, encoded)
+	}
+	block["transactions"] = encodedTxs
+	block["uncles"] = []interface{}{}
+	return header.Hash(), block
+}
+
+func TestBlobFollower(t *testing.T) {
+	sidecars := testBlobSidecars(t, 9)
+	blockRoot := sidecars[0].BlockRoot()
+	hashes := []common.Hash{kZGToVersionedHash(sidecars[0].KZGCommitment), kZGToVersionedHash(sidecars[1].KZGCommitment)}
+	txs := []*types.Transaction{signedBlobTx(t, nil, hashes[:1]), signedBlobTx(t, nil, hashes[1:])}
+	blockHash, block := blockJSON(t, txs...)
+	senders := make([]common.Address, len(txs))
+	for i, tx := range txs {
+		senders[i], _ = types.Sender(types.NewCancunSigner(tx.ChainId()), tx)
+	}
+
+	var executionBlock struct {
+		Data struct {
+			Message struct {
+				Body struct {
+					ExecutionPayload struct {
+						BlockHash common.Hash `json:"block_hash"`
+					} `json:"execution_payload"`
+				} `json:"body"`
+			} `json:"message"`
+		} `json:"data"`
+	}
+	executionBlock.Data.Message.Body.ExecutionPayload.BlockHash = blockHash
+	beacon := newTestBeaconServer(t, map[string]interface{}{
+		"/eth/v1/beacon/blob_sidecars/" + blockRoot.Hex():                blobSidecarsResponse(sidecars...),
+		"/eth/v1/beacon/blob_sidecars/" + blockRoot.Hex() + "?indices=0": blobSidecarsResponse(sidecars[0]),
+		"/eth/v2/beacon/blocks/" + blockRoot.Hex():                       executionBlock,
+	})
+	rpc := newTestRPCServer(t, block)
+
+	blobEvent := func(index uint64) []byte {
+		enc, _ := json.Marshal(map[string]string{
+			"block_root":     blockRoot.Hex(),
+			"index":          fmt.Sprint(index),
+			"slot":           "9",
+			"versioned_hash": hashes[index].Hex(),
+		})
+		return enc
+	}
+	headEvent := func(slot uint64) []byte {
+		enc, _ := json.Marshal(map[string]string{"slot": fmt.Sprint(slot), "block": blockRoot.Hex()})
+		return enc
+	}
+	type event struct {
+		topic string
+		data  []byte
+	}
+	tests := []struct {
+		name    string
+		from    string
+		events  []event
+		written [][]string
+	}{
+		{
+			name: "dedup",
+			events: []event{
+				{"blob_sidecar", blobEvent(0)},
+				{"blob_sidecar", blobEvent(0)},
+				{"head", headEvent(9)},
+				{"head", headEvent(10)},
+				{"head", headEvent(9 + followSeenSlots + 1)},
+			},
+			written: [][]string{{"9-0.bin"}, nil, {"9-1.bin"}, nil, {"9-0.bin", "9-1.bin"}},
+		},
+		{
+			name: "sender filter",
+			from: senders[1].Hex(),
+			events: []event{
+				{"blob_sidecar", blobEvent(0)},
+				{"head", headEvent(9)},
+				{"head", headEvent(10)},
+			},
+			written: [][]string{nil, {"9-1.bin"}, nil},
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writer := &blobWriter{outputDir: dir, codec: legacyCodec{}}
+		f, err := newBlobFollower(ctx, newBeaconClient(beacon.URL), rpc.URL, writer, tt.from, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, e := range tt.events {
+			if err := f.handleEvent(ctx, e.topic, e.data); err != nil {
+				t.Fatalf("%s: (%d) %v", tt.name, i, err)
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var written []string
+			for _, entry := range entries {
+				written = append(written, entry.Name())
+				os.Remove(filepath.Join(dir, entry.Name()))
+			}
+			if !reflect.DeepEqual(written, tt.written[i]) {
+				t.Fatalf("%s: (%d) expected to write %v, wrote %v", tt.name, i, tt.written[i], written)
+			}
+		}
+	}
+
+	if _, err := newBlobFollower(ctx, newBeaconClient(beacon.URL), rpc.URL, nil, "0x1234", ""); err == nil {
+		t.Fatal("expected invalid sender address")
+	}
+}
+
+func TestVersionedHashPrefix(t *testing.T) {
+	versionedHash := common.HexToHash("0x01abcdef00000000000000000000000000000000000000000000000000000000")
+	tests := []struct {
+		prefix  string
+		matches bool
+		invalid bool
+	}{
+		{prefix: "", matches: true},
+		{prefix: "0x01", matches: true},
+		{prefix: "0x01a", matches: true},
+		{prefix: "0x01AB", matches: true},
+		{prefix: "01abc", matches: true},
+		{prefix: "0x01b", matches: false},
+		{prefix: "0x02", matches: false},
+		{prefix: "0x01g", invalid: true},
+		{prefix: "0x" + strings.Repeat("0", 65), invalid: true},
+	}
+	for _, tt := range tests {
+		f, err := newBlobFollower(context.Background(), nil, "", nil, "", tt.prefix)
+		if (err != nil) != tt.invalid {
+			t.Fatalf("%s: unexpected error %v", tt.prefix, err)
+		}
+		if !tt.invalid && f.matchesPrefix(versionedHash) != tt.matches {
+			t.Fatalf("%s: expected match %v", tt.prefix, tt.matches)
+		}
+	}
+}
@@ -1,13 +1,23 @@
 package main
 
-/*
-type rpcHandler func(context.Context, interface{}, network.Stream) error
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+)
+
+// rpcHandler handles a decoded request and returns the SSZ encoded response
+type rpcHandler func(req []byte) ([]byte, error)
 
 // adapted from prysm's handler router - https://github.com/prysmaticlabs/prysm/blob/4e28192541625e2e7828928430dbc72eb6c075c4/beacon-chain/sync/rpc.go#L109
-func setHandler(h host.Host, baseTopic string, handler rpcHandler) {
-	encoding := &encoder.SszNetworkEncoder{}
-	topic := baseTopic + encoding.ProtocolSuffix()
-	h.SetStreamHandler(protocol.ID(topic), func(stream network.Stream) {
+// maxReqSize is the maximum size of the request. Requests of topics without a request body are not read if it's 0.
+func (p *beaconPeer) setHandler(topic string, maxReqSize uint64, handler rpcHandler) {
+	p.host.SetStreamHandler(rpcProtocolID(topic), func(stream network.Stream) {
 		defer func() {
 			if r := recover(); r != nil {
 				log.Printf("Panic occurred: %v", r)
@@ -25,97 +35,101 @@ func setHandler(h host.Host, baseTopic string, handler rpcHandler) {
 			_ = _err
 		}()
 
-		base, ok := p2p.RPCTopicMappings[baseTopic]
-		if !ok {
-			log.Printf("ERROR: Could not retrieve base message for topic %s", baseTopic)
-			return
-		}
-		bb := base
-		t := reflect.TypeOf(base)
-		// Copy Base
-		base = reflect.New(t)
-
-		if baseTopic == p2p.RPCMetaDataTopicV1 || baseTopic == p2p.RPCMetaDataTopicV2 {
-			if err := metadataHandler(context.Background(), base, stream); err != nil {
-				if err != types.ErrWrongForkDigestVersion {
-					log.Printf("ERROR: Could not handle p2p RPC: %v", err)
-				}
-			}
-			return
-		}
-
-		// Given we have an input argument that can be pointer or the actual object, this gives us
-		// a way to check for its reflect.Kind and based on the result, we can decode
-		// accordingly.
-		if t.Kind() == reflect.Ptr {
-			msg, ok := reflect.New(t.Elem()).Interface().(ssz.Unmarshaler)
-			if !ok {
-				log.Printf("ERROR: message of %T ptr does not support marshaller interface. topic=%s", bb, baseTopic)
+		_ = stream.SetDeadline(time.Now().Add(rpcTimeout))
+		var req []byte
+		if maxReqSize != 0 {
+			var err error
+			if req, err = readChunk(bufio.NewReader(stream), maxReqSize); err != nil {
+				log.Printf("ERROR: could not decode stream message. topic=%s err=%v", topic, err)
 				return
 			}
-			if err := encoding.DecodeWithMaxLength(stream, msg); err != nil {
-				log.Printf("ERROR: could not decode stream message: %v", err)
+		}
+		resp, err := handler(req)
+		if err != nil {
+			if _, err := stream.Write([]byte{responseCodeResourceUnavailable}); err != nil {
 				return
 			}
-			if err := handler(context.Background(), msg, stream); err != nil {
-				if err != types.ErrWrongForkDigestVersion {
-					log.Printf("ERROR: Could not handle p2p RPC: %v", err)
-				}
-			}
-		} else {
-			nTyp := reflect.New(t)
-			msg, ok := nTyp.Interface().(ssz.Unmarshaler)
-			if !ok {
-				log.Printf("ERROR: message of %T does not support marshaller interface", msg)
+			if err := writeChunk(stream, []byte(err.Error())); err != nil {
 				return
 			}
-			if err := handler(context.Background(), msg, stream); err != nil {
-				if err != types.ErrWrongForkDigestVersion {
-					log.Printf("ERROR: Could not handle p2p RPC: %v", err)
-				}
-			}
+			closeStream(stream)
+			return
 		}
+		if resp == nil {
+			closeStream(stream)
+			return
+		}
+		if _, err := stream.Write([]byte{responseCodeSuccess}); err != nil {
+			log.Printf("ERROR: Could not handle p2p RPC: %v", err)
+			return
+		}
+		if err := writeChunk(stream, resp); err != nil {
+			log.Printf("ERROR: Could not handle p2p RPC: %v", err)
+			return
+		}
+		closeStream(stream)
 	})
 }
 
-func dummyMetadata() metadata.Metadata {
-	metaData := &ethpb.MetaDataV1{
-		SeqNumber: 0,
-		Attnets:   bitfield.NewBitvector64(),
-		Syncnets:  bitfield.Bitvector4{byte(0x00)},
+func (p *beaconPeer) setHandlers() {
+	p.setHandler(rpcStatusTopicV1, statusSSZSize, p.statusHandler)
+	p.setHandler(rpcGoodbyeTopicV1, 8, goodbyeHandler)
+	p.setHandler(rpcPingTopicV1, 8, pingHandler)
+	p.setHandler(rpcMetaDataTopicV1, 0, metadataHandler(1))
+	p.setHandler(rpcMetaDataTopicV2, 0, metadataHandler(2))
+	p.setHandler(rpcMetaDataTopicV3, 0, metadataHandler(3))
+
+	unavailableHandler := func([]byte) ([]byte, error) {
+		return nil, fmt.Errorf("not serving blocks or blobs")
 	}
-	return wrapper.WrappedMetadataV1(metaData)
+	p.setHandler(rpcBlocksByRangeTopicV2, 16, unavailableHandler)
+	p.setHandler(rpcBlocksByRootTopicV2, maxRequestBlocks*32, unavailableHandler)
+	p.setHandler(rpcBlobSidecarsByRangeTopicV1, 16, unavailableHandler)
+	p.setHandler(rpcBlobSidecarsByRootTopicV1, maxRequestBlocks*maxBlobsPerBlock*40, unavailableHandler)
 }
 
-// pingHandler reads the incoming ping rpc message from the peer.
-func pingHandler(_ context.Context, _ interface{}, stream network.Stream) error {
-	encoding := &encoder.SszNetworkEncoder{}
-	defer closeStream(stream)
-	if _, err := stream.Write([]byte{responseCodeSuccess}); err != nil {
-		return err
+// statusHandler replies to the peer's status with its own status, so that it considers us to be on the same chain
+func (p *beaconPeer) statusHandler(req []byte) ([]byte, error) {
+	theirs := new(status)
+	if err := theirs.UnmarshalSSZ(req); err != nil {
+		return nil, err
 	}
-	m := dummyMetadata()
-	sq := consensustypes.SSZUint64(m.SequenceNumber())
-	if _, err := encoding.EncodeWithMaxLength(stream, &sq); err != nil {
-		return fmt.Errorf("%w: pingHandler stream write", err)
+	select {
+	case p.peerStatus <- theirs:
+	default:
 	}
-	return nil
+	return req, nil
 }
 
-// metadataHandler spoofs a valid looking metadata message
-func metadataHandler(_ context.Context, _ interface{}, stream network.Stream) error {
-	encoding := &encoder.SszNetworkEncoder{}
-	defer closeStream(stream)
-	if _, err := stream.Write([]byte{responseCodeSuccess}); err != nil {
-		return err
+// goodbyeHandler logs the reason the peer is disconnecting
+func goodbyeHandler(req []byte) ([]byte, error) {
+	if len(req) != 8 {
+		return nil, fmt.Errorf("invalid goodbye reason size %d", len(req))
 	}
+	log.Printf("peer said goodbye. reason=%d", binary.LittleEndian.Uint64(req))
+	return nil, nil
+}
+
+// pingHandler replies to the peer's ping with our metadata sequence number
+func pingHandler([]byte) ([]byte, error) {
+	return binary.LittleEndian.AppendUint64(nil, 0), nil
+}
 
-	// write a dummy metadata message to satify the client handshake
-	m := dummyMetadata()
-	if _, err := encoding.EncodeWithMaxLength(stream, m); err != nil {
-		return fmt.Errorf("%w: metadata stream write", err)
+// metadataHandler spoofs a valid looking metadata message of the given version
+func metadataHandler(version int) rpcHandler {
+	return func([]byte) ([]byte, error) {
+		// seq_number and an empty attnets bitvector
+		m := make([]byte, 8+8)
+		if version >= 2 {
+			// empty syncnets bitvector
+			m = append(m, 0)
+		}
+		if version >= 3 {
+			// custody_group_count
+			m = binary.LittleEndian.AppendUint64(m, 0)
+		}
+		return m, nil
 	}
-	return nil
 }
 
 func closeStream(stream network.Stream) {
@@ -123,4 +137,3 @@ func closeStream(stream network.Stream) {
 		log.Println(err)
 	}
 }
-*/
\ No newline at end of file
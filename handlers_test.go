@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestGoodbyeHandler(t *testing.T) {
+	tests := []struct {
+		req   []byte
+		valid bool
+	}{
+		{req: binary.LittleEndian.AppendUint64(nil, 1), valid: true},
+		{req: nil},
+		{req: []byte{1, 0, 0}},
+		{req: make([]byte, 9)},
+	}
+	for i, tt := range tests {
+		resp, err := goodbyeHandler(tt.req)
+		if (err == nil) != tt.valid {
+			t.Fatalf("(%d) unexpected error %v", i, err)
+		}
+		if resp != nil {
+			t.Fatalf("(%d) unexpected response %x", i, resp)
+		}
+	}
+}
+
+func TestMetadataHandler(t *testing.T) {
+	for version, size := range map[int]int{1: 16, 2: 17, 3: 25} {
+		resp, err := metadataHandler(version)(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp) != size {
+			t.Fatalf("expected metadata/%d of %d bytes, got %d", version, size, len(resp))
+		}
+	}
+	if resp, err := pingHandler(binary.LittleEndian.AppendUint64(nil, 1)); err != nil || len(resp) != 8 {
+		t.Fatalf("unexpected ping response %x: %v", resp, err)
+	}
+}
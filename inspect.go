@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/urfave/cli"
+)
+
+// blobReport describes the content of a blob
+type blobReport struct {
+	VersionedHash      *common.Hash `json:"versioned_hash,omitempty"`
+	Codec              string       `json:"codec"`
+	PayloadLength      int          `json:"payload_length"`
+	FillRatio          float64      `json:"fill_ratio"`
+	ZeroFieldElements  int          `json:"zero_field_elements"`
+	Entropy            float64      `json:"entropy"`
+	Canonical          bool         `json:"canonical"`
+	NonCanonicalFields []int        `json:"non_canonical_field_elements,omitempty"`
+}
+
+// detectableCodecs are tried in order by detectBlobCodec. Codecs with a version and length header come first, as
+// the legacy codec accepts any blob whose field elements all end with a zero byte.
+var detectableCodecs = []struct {
+	name     string
+	codec    BlobCodec
+	capacity int
+}{
+	{"lossless", losslessCodec{}, losslessBlobCapacity},
+	{"dense", denseCodec{}, denseBlobCapacity},
+	{"op", opCodec{}, opBlobCapacity},
+	{"legacy", legacyCodec{}, paddedBlobCapacity},
+}
+
+func InspectApp(cliCtx *cli.Context) error {
+	var (
+		blob          []byte
+		versionedHash *common.Hash
+		err           error
+	)
+	switch {
+	case cliCtx.IsSet(InspectBlobFileFlag.Name):
+		blob, err = readBlobFile(cliCtx.String(InspectBlobFileFlag.Name))
+	case cliCtx.IsSet(InspectVersionedHashFlag.Name):
+		if !cliCtx.IsSet(InspectStoreDirFlag.Name) {
+			return fmt.Errorf("--%s is required with --%s", InspectStoreDirFlag.Name, InspectVersionedHashFlag.Name)
+		}
+		hash := common.HexToHash(cliCtx.String(InspectVersionedHashFlag.Name))
+		versionedHash = &hash
+		blob, err = readStoredBlob(cliCtx.String(InspectStoreDirFlag.Name), hash)
+	default:
+		return fmt.Errorf("either --%s or --%s is required", InspectBlobFileFlag.Name, InspectVersionedHashFlag.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	report := inspectBlob(blob)
+	if versionedHash != nil {
+		report.VersionedHash = versionedHash
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// readBlobFile reads a blob file holding the raw blob or its hex encoding, as returned by the beacon node API
+func readBlobFile(name string) ([]byte, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading blob file: %v", err)
+	}
+	if len(data) == blobSize {
+		return data, nil
+	}
+	blob, err := hexutil.Decode(string(bytes.TrimSpace(data)))
+	if err != nil || len(blob) != blobSize {
+		return nil, fmt.Errorf("blob file must hold a %d byte blob, raw or hex encoded", blobSize)
+	}
+	return blob, nil
+}
+
+func readStoredBlob(dir string, versionedHash common.Hash) ([]byte, error) {
+	store, err := openBlobStoreReadOnly(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	return store.Get(versionedHash)
+}
+
+// inspectBlob detects the codec of a blob and gathers statistics about its payload and field elements. The versioned
+// hash is only computed for canonical blobs, as others can't be committed to.
+func inspectBlob(blob []byte) *blobReport {
+	report := &blobReport{Canonical: true}
+	for i := 0; i < params.BlobTxFieldElementsPerBlob; i++ {
+		fe := blob[i*32 : (i+1)*32]
+		if isZero(fe) {
+			report.ZeroFieldElements++
+		}
+		if !isCanonicalFieldElement(fe) {
+			report.Canonical = false
+			report.NonCanonicalFields = append(report.NonCanonicalFields, i)
+		}
+	}
+
+	codec, payload, capacity := detectBlobCodec(blob)
+	report.Codec = codec
+	report.PayloadLength = len(payload)
+	report.FillRatio = float64(len(payload)) / float64(capacity)
+	report.Entropy = shannonEntropy(payload)
+
+	if report.Canonical {
+		commitment, err := kzg4844.BlobToCommitment(kzg4844.Blob(blob))
+		if err == nil {
+			versionedHash := kZGToVersionedHash(commitment)
+			report.VersionedHash = &versionedHash
+		}
+	}
+	return report
+}
+
+// detectBlobCodec returns the name of the codec that most likely encoded the blob along with its payload and the
+// capacity of the codec. Blobs no codec can decode are raw, and their payload ends at the last non-zero byte.
+func detectBlobCodec(blob []byte) (string, []byte, int) {
+	if isZero(blob) {
+		return "empty", nil, blobSize
+	}
+	for _, c := range detectableCodecs {
+		if c.name == "legacy" && !hasZeroLastBytes(blob) {
+			continue
+		}
+		if payload, err := c.codec.Decode(blob); err == nil {
+			return c.name, payload, c.capacity
+		}
+	}
+	end := len(blob)
+	for end > 0 && blob[end-1] == 0 {
+		end--
+	}
+	return "raw", blob[:end], blobSize
+}
+
+// hasZeroLastBytes reports whether the last byte of every field element is zero, which the legacy codec leaves unused
+func hasZeroLastBytes(blob []byte) bool {
+	for i := 31; i < len(blob); i += 32 {
+		if blob[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// shannonEntropy returns the entropy of data in bits per byte
+func shannonEntropy(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+	var entropy float64
+	for _, n := range counts {
+		if n != 0 {
+			p := float64(n) / float64(len(data))
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
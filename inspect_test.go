@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestDetectBlobCodec(t *testing.T) {
+	data := []byte("hello blobs")
+	for _, name := range []string{"legacy", "lossless", "dense", "op"} {
+		blobs, err := blobCodecs[name].Encode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		report := inspectBlob(blobs[0][:])
+		if report.Codec != name || report.PayloadLength != len(data) || !report.Canonical || report.VersionedHash == nil {
+			t.Fatalf("%s: unexpected report %+v", name, report)
+		}
+	}
+
+	var blob [blobSize]byte
+	if report := inspectBlob(blob[:]); report.Codec != "empty" || report.ZeroFieldElements != 4096 || report.Entropy != 0 {
+		t.Fatalf("unexpected report of empty blob %+v", report)
+	}
+
+	// the top byte of a field element exceeds the modulus
+	blob[64] = 0xff
+	blob[127] = 0x01
+	report := inspectBlob(blob[:])
+	if report.Codec != "raw" || report.PayloadLength != 128 || report.Canonical || report.VersionedHash != nil ||
+		len(report.NonCanonicalFields) != 1 || report.NonCanonicalFields[0] != 2 || report.ZeroFieldElements != 4094 {
+		t.Fatalf("unexpected report of raw blob %+v", report)
+	}
+}
+
+func TestShannonEntropy(t *testing.T) {
+	if e := shannonEntropy([]byte{1, 1, 1, 1}); e != 0 {
+		t.Fatalf("expected no entropy, got %v", e)
+	}
+	b := make([]byte, 256)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	if e := shannonEntropy(b); e != 8 {
+		t.Fatalf("expected 8 bits per byte, got %v", e)
+	}
+}
+
+func TestReadStoredBlob(t *testing.T) {
+	dir := t.TempDir()
+	store, err := openBlobStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sidecar := testBlobSidecars(t, 1)[0]
+	if _, err := store.Put(sidecar); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+	// a partially written line is left as is
+	indexFile := filepath.Join(dir, blobStoreIndexFile)
+	index, err := os.OpenFile(indexFile, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := index.Write([]byte(`{"versioned_hash":`)); err != nil {
+		t.Fatal(err)
+	}
+	index.Close()
+	before, err := os.ReadFile(indexFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blob, err := readStoredBlob(dir, kZGToVersionedHash(sidecar.KZGCommitment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(blob, sidecar.Blob[:]) {
+		t.Fatal("unexpected blob")
+	}
+	if after, err := os.ReadFile(indexFile); err != nil || !bytes.Equal(after, before) {
+		t.Fatalf("expected the index to be left unchanged: %v", err)
+	}
+	if _, err := readStoredBlob(dir, common.Hash{1}); err == nil {
+		t.Fatal("expected unknown blob")
+	}
+
+	missing := filepath.Join(dir, "missing")
+	if _, err := readStoredBlob(missing, common.Hash{1}); err == nil {
+		t.Fatal("expected missing blob store")
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Fatal("expected missing blob store not to be created")
+	}
+}
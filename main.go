@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/hex"
 	"encoding/json"
@@ -31,9 +30,15 @@ func main() {
 			Action: TxApp,
 			Flags:  TxFlags,
 		},
+		{
+			Name:   "send-raw",
+			Usage:  "validate and send a signed blob transaction",
+			Action: SendRawApp,
+			Flags:  SendRawFlags,
+		},
 		{
 			Name:   "download",
-			Usage:  "download blobs from the beacon net",
+			Usage:  "download blobs from a beacon node",
 			Action: DownloadApp,
 			Flags:  DownloadFlags,
 		},
@@ -43,6 +48,36 @@ func main() {
 			Action: ProofApp,
 			Flags:  ProofFlags,
 		},
+		{
+			Name:   "inspect",
+			Usage:  "detect the codec of a blob and report statistics about its content",
+			Action: InspectApp,
+			Flags:  InspectFlags,
+		},
+		{
+			Name:   "verify",
+			Usage:  "verify the commitment, proof and versioned hash of a blob",
+			Action: VerifyApp,
+			Flags:  VerifyFlags,
+		},
+		{
+			Name:   "polynomial",
+			Usage:  "convert a blob to the coefficients of its polynomial and back",
+			Action: PolynomialApp,
+			Flags:  PolynomialFlags,
+		},
+		{
+			Name:   "cells",
+			Usage:  "extend blobs to cells with their proofs, verify cells and recover blobs from half of them",
+			Action: CellsApp,
+			Flags:  CellsFlags,
+		},
+		{
+			Name:   "recover",
+			Usage:  "recover blobs and their payload from cells or data column sidecars of several sources",
+			Action: RecoverApp,
+			Flags:  RecoverFlags,
+		},
 	}
 
 	err := app.Run(os.Args)
@@ -65,6 +100,24 @@ func TxApp(cliCtx *cli.Context) error {
 	chainID := cliCtx.String(TxChainID.Name)
 	calldata := cliCtx.String(TxCalldata.Name)
 
+	codec, err := lookupBlobCodec(cliCtx.String(TxCodecFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	offline := cliCtx.Bool(TxOfflineFlag.Name)
+	if offline {
+		// nothing can be looked up without a node, and defaults are too risky to sign with
+		for _, flag := range []string{TxNonceFlag.Name, TxGasPriceFlag.Name, TxPriorityGasPrice.Name, TxMaxFeePerBlobGas.Name, TxChainID.Name, TxOutFlag.Name} {
+			if !cliCtx.IsSet(flag) {
+				return fmt.Errorf("--%s is required with --%s", flag, TxOfflineFlag.Name)
+			}
+		}
+		if nonce < 0 {
+			return fmt.Errorf("invalid nonce %d", nonce)
+		}
+	}
+
 	value256, err := uint256.FromHex(value)
 	if err != nil {
 		return fmt.Errorf("invalid value param: %v", err)
@@ -75,12 +128,33 @@ func TxApp(cliCtx *cli.Context) error {
 		return fmt.Errorf("error reading blob file: %v", err)
 	}
 
-	chainId, _ := new(big.Int).SetString(chainID, 0)
+	var blobs []gethkzg4844.Blob
+	if cliCtx.Bool(TxRawBlobFlag.Name) {
+		blobs, err = splitRawBlobs(data)
+	} else {
+		blobs, err = codec.Encode(data)
+	}
+	if err != nil {
+		return err
+	}
+	sidecarVersion := cliCtx.Uint64(TxSidecarVersionFlag.Name)
+	sidecar, versionedHashes, err := newBlobTxSidecar(blobs, sidecarVersion)
+	if err != nil {
+		return err
+	}
+
+	chainId, ok := new(big.Int).SetString(chainID, 0)
+	if !ok {
+		return fmt.Errorf("invalid chain id %s", chainID)
+	}
 
 	ctx := context.Background()
-	client, err := ethclient.DialContext(ctx, addr)
-	if err != nil {
-		log.Fatalf("Failed to connect to the Ethereum client: %v", err)
+	var client *ethclient.Client
+	if !offline {
+		client, err = ethclient.DialContext(ctx, addr)
+		if err != nil {
+			log.Fatalf("Failed to connect to the Ethereum client: %v", err)
+		}
 	}
 
 	key, err := crypto.HexToECDSA(prv)
@@ -127,11 +201,6 @@ func TxApp(cliCtx *cli.Context) error {
 		return fmt.Errorf("%w: invalid max_fee_per_blob_gas", err)
 	}
 
-	blobs, commitments, proofs, versionedHashes, err := EncodeBlobs(data)
-	if err != nil {
-		log.Fatalf("failed to compute commitments: %v", err)
-	}
-
 	calldataBytes, err := common.ParseHexOrString(calldata)
 	if err != nil {
 		log.Fatalf("failed to parse calldata: %v", err)
@@ -148,10 +217,13 @@ func TxApp(cliCtx *cli.Context) error {
 		Data:       calldataBytes,
 		BlobFeeCap: maxFeePerBlobGas256,
 		BlobHashes: versionedHashes,
-		Sidecar:    &types.BlobTxSidecar{Blobs: blobs, Commitments: commitments, Proofs: proofs},
+		Sidecar:    sidecar,
 	})
 	signedTx, _ := types.SignTx(tx, types.NewCancunSigner(chainId), key)
-	err = client.SendTransaction(context.Background(), signedTx)
+	if offline {
+		return writeOfflineTx(cliCtx.String(TxOutFlag.Name), signedTx, sidecarVersion)
+	}
+	err = sendBlobTx(context.Background(), client, signedTx, sidecarVersion)
 
 	if err != nil {
 		log.Fatalf("failed to send transaction: %v", err)
@@ -159,9 +231,17 @@ func TxApp(cliCtx *cli.Context) error {
 		log.Printf("successfully sent transaction. txhash=%v", signedTx.Hash())
 	}
 
+	waitForReceipt(client, signedTx.Hash())
+	log.Printf("Transaction included. nonce=%d hash=%v", nonce, signedTx.Hash())
+	//log.Printf("Transaction included. nonce=%d hash=%v, block=%d", nonce, signedTx.Hash(), receipt.BlockNumber.Int64())
+	return nil
+}
+
+// waitForReceipt polls the node until the transaction with the given hash is included
+func waitForReceipt(client *ethclient.Client, hash common.Hash) {
 	//var receipt *types.Receipt
 	for {
-		_, err = client.TransactionReceipt(context.Background(), tx.Hash())
+		_, err := client.TransactionReceipt(context.Background(), hash)
 		if err == ethereum.NotFound {
 			time.Sleep(1 * time.Second)
 		} else if err != nil {
@@ -173,10 +253,6 @@ func TxApp(cliCtx *cli.Context) error {
 			break
 		}
 	}
-
-	log.Printf("Transaction included. nonce=%d hash=%v", nonce, tx.Hash())
-	//log.Printf("Transaction included. nonce=%d hash=%v, block=%d", nonce, tx.Hash(), receipt.BlockNumber.Int64())
-	return nil
 }
 
 func ProofApp(cliCtx *cli.Context) error {
@@ -184,13 +260,36 @@ func ProofApp(cliCtx *cli.Context) error {
 	blobIndex := cliCtx.Uint64(ProofBlobIndexFlag.Name)
 	inputPoint := cliCtx.String(ProofInputPointFlag.Name)
 
+	codec, err := lookupBlobCodec(cliCtx.String(ProofCodecFlag.Name))
+	if err != nil {
+		return err
+	}
+
 	data, err := os.ReadFile(file)
 	if err != nil {
 		return fmt.Errorf("error reading blob file: %v", err)
 	}
-	blobs, commitments, _, versionedHashes, err := EncodeBlobs(data)
+	blobs, err := codec.Encode(data)
 	if err != nil {
-		log.Fatalf("failed to compute commitments: %v", err)
+		return err
+	}
+	// the blob proofs are never needed, and evaluations don't need commitments unless they are used to compute the
+	// challenge
+	var (
+		commitments     []gethkzg4844.Commitment
+		versionedHashes []common.Hash
+	)
+	if !cliCtx.Bool(ProofEvalOnlyFlag.Name) || cliCtx.Bool(ProofChallengeFlag.Name) {
+		if commitments, versionedHashes, err = BlobCommitments(blobs); err != nil {
+			return err
+		}
+	}
+
+	if cliCtx.Bool(ProofAllBlobsFlag.Name) || cliCtx.IsSet(ProofPointsFileFlag.Name) || cliCtx.Bool(ProofChallengeFlag.Name) {
+		return batchProofs(cliCtx, blobs, commitments, versionedHashes)
+	}
+	if !cliCtx.IsSet(ProofBlobIndexFlag.Name) || !cliCtx.IsSet(ProofInputPointFlag.Name) {
+		return fmt.Errorf("--%s and --%s are required", ProofBlobIndexFlag.Name, ProofInputPointFlag.Name)
 	}
 
 	if blobIndex >= uint64(len(blobs)) {
@@ -204,21 +303,26 @@ func ProofApp(cliCtx *cli.Context) error {
 	var x gethkzg4844.Point
 	ip, _ := hex.DecodeString(inputPoint)
 	copy(x[:], ip)
+	if cliCtx.Bool(ProofEvalOnlyFlag.Name) {
+		y, err := EvaluateBlob(blobs[blobIndex], x)
+		if err != nil {
+			return err
+		}
+		log.Printf("\nx %x \n"+"y %x", x[:], y[:])
+		return nil
+	}
 	proof, claimedValue, err := gethkzg4844.ComputeProof(gethkzg4844.Blob(blobs[blobIndex]), x)
 	if err != nil {
 		log.Fatalf("failed to compute proofs: %v", err)
 	}
 
-	pointEvalInput := bytes.Join(
-		[][]byte{
-			versionedHashes[blobIndex][:],
-			x[:],
-			claimedValue[:],
-			commitments[blobIndex][:],
-			proof[:],
-		},
-		[]byte{},
-	)
+	pointEvalInput := (&pointEvaluationInput{
+		VersionedHash: versionedHashes[blobIndex],
+		Z:             x,
+		Y:             claimedValue,
+		Commitment:    commitments[blobIndex],
+		Proof:         proof,
+	}).Bytes()
 	log.Printf(
 		"\nversionedHash %x \n"+"x %x \n"+"y %x \n"+"commitment %x \n"+"proof %x \n"+"pointEvalInput %x",
 		versionedHashes[blobIndex][:], x[:], claimedValue[:], commitments[blobIndex][:], proof[:], pointEvalInput[:])
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// offlineTxSummary describes a transaction signed with tx --offline, to be reviewed before it is broadcast
+type offlineTxSummary struct {
+	Hash                 common.Hash     `json:"hash"`
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to"`
+	ChainID              *hexutil.Big    `json:"chain_id"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	MaxFeePerGas         *hexutil.Big    `json:"max_fee_per_gas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"max_priority_fee_per_gas"`
+	MaxFeePerBlobGas     *hexutil.Big    `json:"max_fee_per_blob_gas"`
+	Value                *hexutil.Big    `json:"value"`
+	BlobVersionedHashes  []common.Hash   `json:"blob_versioned_hashes"`
+	SidecarVersion       uint64          `json:"sidecar_version"`
+	RawFile              string          `json:"raw_file"`
+	RawLength            int             `json:"raw_length"`
+}
+
+// writeOfflineTx writes the network encoding of a signed blob transaction to out, and its summary to a JSON file
+// named after it
+func writeOfflineTx(out string, tx *types.Transaction, sidecarVersion uint64) error {
+	raw, err := marshalBlobTx(tx, sidecarVersion)
+	if err != nil {
+		return err
+	}
+	from, err := types.Sender(types.NewCancunSigner(tx.ChainId()), tx)
+	if err != nil {
+		return fmt.Errorf("%w: invalid signature", err)
+	}
+	summary := &offlineTxSummary{
+		Hash:                 tx.Hash(),
+		From:                 from,
+		To:                   tx.To(),
+		ChainID:              (*hexutil.Big)(tx.ChainId()),
+		Nonce:                hexutil.Uint64(tx.Nonce()),
+		Gas:                  hexutil.Uint64(tx.Gas()),
+		MaxFeePerGas:         (*hexutil.Big)(tx.GasFeeCap()),
+		MaxPriorityFeePerGas: (*hexutil.Big)(tx.GasTipCap()),
+		MaxFeePerBlobGas:     (*hexutil.Big)(tx.BlobGasFeeCap()),
+		Value:                (*hexutil.Big)(tx.Value()),
+		BlobVersionedHashes:  tx.BlobHashes(),
+		SidecarVersion:       sidecarVersion,
+		RawFile:              filepath.Base(out),
+		RawLength:            len(raw),
+	}
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(out, raw, 0644); err != nil {
+		return fmt.Errorf("%w: unable to write transaction", err)
+	}
+	summaryFile := offlineSummaryFile(out)
+	if err := os.WriteFile(summaryFile, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("%w: unable to write transaction summary", err)
+	}
+	log.Printf("signed transaction written. txhash=%v nonce=%d out=%s summary=%s", summary.Hash, tx.Nonce(), out, summaryFile)
+	return nil
+}
+
+// offlineSummaryFile replaces the extension of the transaction file with .json
+func offlineSummaryFile(out string) string {
+	summary := strings.TrimSuffix(out, filepath.Ext(out)) + ".json"
+	if summary == out {
+		return out + ".json"
+	}
+	return summary
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+func TestWriteOfflineTx(t *testing.T) {
+	tx := signedBlobTx(t, &types.BlobTxSidecar{
+		Blobs:       make([]kzg4844.Blob, 2),
+		Commitments: []kzg4844.Commitment{{1}, {2}},
+		Proofs:      make([]kzg4844.Proof, 2),
+	}, []common.Hash{{1}, {2}})
+	out := filepath.Join(t.TempDir(), "tx.rlp")
+	if err := writeOfflineTx(out, tx, sidecarVersion0); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, _, err := unmarshalBlobTx(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Hash() != tx.Hash() || decoded.BlobTxSidecar() == nil {
+		t.Fatal("unexpected transaction")
+	}
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(out), "tx.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary offlineTxSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatal(err)
+	}
+	from, _ := types.Sender(types.NewCancunSigner(tx.ChainId()), tx)
+	if summary.Hash != tx.Hash() || summary.From != from || uint64(summary.Nonce) != tx.Nonce() ||
+		len(summary.BlobVersionedHashes) != 2 || summary.RawLength != len(raw) {
+		t.Fatalf("unexpected summary %s", data)
+	}
+
+	if _, err := os.Stat(out + ".json"); !os.IsNotExist(err) {
+		t.Fatal("unexpected summary file")
+	}
+	if name := offlineSummaryFile("tx.json"); name != "tx.json.json" {
+		t.Fatalf("unexpected summary file %s", name)
+	}
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	singularBatchType = 0
+	spanBatchType     = 1
+
+	// maxSpanBatchElementCount bounds the number of blocks and transactions of a span batch
+	maxSpanBatchElementCount = 10_000_000
+)
+
+// singularBatch is the batch of a single L2 block
+type singularBatch struct {
+	Type         string          `json:"type" rlp:"-"`
+	ParentHash   common.Hash     `json:"parent_hash"`
+	EpochNum     uint64          `json:"epoch_num"`
+	EpochHash    common.Hash     `json:"epoch_hash"`
+	Timestamp    uint64          `json:"timestamp"`
+	Transactions []hexutil.Bytes `json:"transactions"`
+}
+
+// spanBatch is the batch of a range of L2 blocks introduced with the Delta upgrade
+type spanBatch struct {
+	Type          string           `json:"type"`
+	RelTimestamp  uint64           `json:"rel_timestamp"`
+	L1OriginNum   uint64           `json:"l1_origin_num"`
+	ParentCheck   hexutil.Bytes    `json:"parent_check"`
+	L1OriginCheck hexutil.Bytes    `json:"l1_origin_check"`
+	Blocks        []spanBatchBlock `json:"blocks"`
+}
+
+type spanBatchBlock struct {
+	EpochNum     uint64        `json:"epoch_num"`
+	Transactions []spanBatchTx `json:"transactions"`
+}
+
+// spanBatchTx is a transaction of a span batch. The chain ID isn't part of the batch, so the transaction can't be
+// hashed or have its sender recovered without the rollup config.
+type spanBatchTx struct {
+	Type                 uint8            `json:"type"`
+	To                   *common.Address  `json:"to"`
+	Nonce                uint64           `json:"nonce"`
+	Gas                  uint64           `json:"gas"`
+	Value                *hexutil.Big     `json:"value"`
+	GasPrice             *hexutil.Big     `json:"gas_price,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big     `json:"max_priority_fee_per_gas,omitempty"`
+	MaxFeePerGas         *hexutil.Big     `json:"max_fee_per_gas,omitempty"`
+	Data                 hexutil.Bytes    `json:"data"`
+	AccessList           types.AccessList `json:"access_list,omitempty"`
+	AuthorizationList    hexutil.Bytes    `json:"authorization_list,omitempty"`
+	R                    common.Hash      `json:"r"`
+	S                    common.Hash      `json:"s"`
+	YParity              uint             `json:"y_parity"`
+	Protected            *bool            `json:"protected,omitempty"`
+}
+
+// decodeOPBatch decodes a batch read from a channel, which is prefixed with its type
+func decodeOPBatch(data []byte) (any, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty batch")
+	}
+	switch data[0] {
+	case singularBatchType:
+		batch := &singularBatch{Type: "singular"}
+		if err := rlp.DecodeBytes(data[1:], batch); err != nil {
+			return nil, err
+		}
+		return batch, nil
+	case spanBatchType:
+		return decodeSpanBatch(data[1:])
+	default:
+		return nil, fmt.Errorf("unsupported batch type %d", data[0])
+	}
+}
+
+// decodeSpanBatch decodes the prefix and payload of a span batch
+func decodeSpanBatch(data []byte) (*spanBatch, error) {
+	r := bytes.NewReader(data)
+	b := &spanBatch{
+		Type:          "span",
+		ParentCheck:   make([]byte, 20),
+		L1OriginCheck: make([]byte, 20),
+	}
+	var err error
+	if b.RelTimestamp, err = binary.ReadUvarint(r); err != nil {
+		return nil, fmt.Errorf("%w: invalid rel_timestamp", err)
+	}
+	if b.L1OriginNum, err = binary.ReadUvarint(r); err != nil {
+		return nil, fmt.Errorf("%w: invalid l1_origin_num", err)
+	}
+	if _, err := io.ReadFull(r, b.ParentCheck); err != nil {
+		return nil, fmt.Errorf("%w: invalid parent_check", err)
+	}
+	if _, err := io.ReadFull(r, b.L1OriginCheck); err != nil {
+		return nil, fmt.Errorf("%w: invalid l1_origin_check", err)
+	}
+
+	blockCount, err := readSpanBatchCount(r, "block_count")
+	if err != nil {
+		return nil, err
+	}
+	if blockCount == 0 {
+		return nil, errors.New("span batch has no blocks")
+	}
+	originBits, err := readBitlist(r, blockCount)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid origin_bits", err)
+	}
+	b.Blocks = make([]spanBatchBlock, blockCount)
+	var txCount uint64
+	for i := range b.Blocks {
+		count, err := readSpanBatchCount(r, "block_tx_count")
+		if err != nil {
+			return nil, err
+		}
+		txCount += count
+		if txCount > maxSpanBatchElementCount {
+			return nil, errors.New("span batch has too many transactions")
+		}
+		b.Blocks[i].Transactions = make([]spanBatchTx, count)
+	}
+	// the L1 origin number is that of the last block, and each origin bit marks a block whose origin changed
+	epoch := b.L1OriginNum
+	for i := len(b.Blocks) - 1; i >= 0; i-- {
+		b.Blocks[i].EpochNum = epoch
+		if originBits.Bit(i) == 1 && i > 0 {
+			epoch--
+		}
+	}
+
+	txs := make([]*spanBatchTx, 0, txCount)
+	for i := range b.Blocks {
+		for j := range b.Blocks[i].Transactions {
+			txs = append(txs, &b.Blocks[i].Transactions[j])
+		}
+	}
+	if err := decodeSpanBatchTxs(r, txs); err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d bytes left after span batch", r.Len())
+	}
+	return b, nil
+}
+
+// decodeSpanBatchTxs decodes the transactions of a span batch, which are stored field by field
+func decodeSpanBatchTxs(r *bytes.Reader, txs []*spanBatchTx) error {
+	n := uint64(len(txs))
+	contractCreationBits, err := readBitlist(r, n)
+	if err != nil {
+		return fmt.Errorf("%w: invalid contract_creation_bits", err)
+	}
+	yParityBits, err := readBitlist(r, n)
+	if err != nil {
+		return fmt.Errorf("%w: invalid y_parity_bits", err)
+	}
+	for i, tx := range txs {
+		if _, err := io.ReadFull(r, tx.R[:]); err != nil {
+			return fmt.Errorf("%w: invalid signature of tx %d", err, i)
+		}
+		if _, err := io.ReadFull(r, tx.S[:]); err != nil {
+			return fmt.Errorf("%w: invalid signature of tx %d", err, i)
+		}
+		tx.YParity = yParityBits.Bit(i)
+	}
+	for i, tx := range txs {
+		if contractCreationBits.Bit(i) == 1 {
+			continue
+		}
+		var to common.Address
+		if _, err := io.ReadFull(r, to[:]); err != nil {
+			return fmt.Errorf("%w: invalid to of tx %d", err, i)
+		}
+		tx.To = &to
+	}
+	var legacyTxs []*spanBatchTx
+	s := rlp.NewStream(r, 0)
+	for i, tx := range txs {
+		if err := decodeSpanBatchTxData(r, s, tx); err != nil {
+			return fmt.Errorf("%w: invalid data of tx %d", err, i)
+		}
+		if tx.Type == types.LegacyTxType {
+			legacyTxs = append(legacyTxs, tx)
+		}
+	}
+	for i, tx := range txs {
+		if tx.Nonce, err = binary.ReadUvarint(r); err != nil {
+			return fmt.Errorf("%w: invalid nonce of tx %d", err, i)
+		}
+	}
+	for i, tx := range txs {
+		if tx.Gas, err = binary.ReadUvarint(r); err != nil {
+			return fmt.Errorf("%w: invalid gas of tx %d", err, i)
+		}
+	}
+	protectedBits, err := readBitlist(r, uint64(len(legacyTxs)))
+	if err != nil {
+		return fmt.Errorf("%w: invalid protected_bits", err)
+	}
+	for i, tx := range legacyTxs {
+		protected := protectedBits.Bit(i) == 1
+		tx.Protected = &protected
+	}
+	return nil
+}
+
+// decodeSpanBatchTxData decodes the RLP encoded data of a transaction, which is prefixed with the transaction type
+// unless it's a legacy transaction
+func decodeSpanBatchTxData(r *bytes.Reader, s *rlp.Stream, tx *spanBatchTx) error {
+	typ, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+	if typ >= 0xc0 {
+		if err := r.UnreadByte(); err != nil {
+			return err
+		}
+		typ = types.LegacyTxType
+	}
+	tx.Type = typ
+	s.Reset(r, 0)
+
+	var value *big.Int
+	switch typ {
+	case types.LegacyTxType:
+		var data struct {
+			Value    *big.Int
+			GasPrice *big.Int
+			Data     []byte
+		}
+		err = s.Decode(&data)
+		value, tx.GasPrice, tx.Data = data.Value, (*hexutil.Big)(data.GasPrice), data.Data
+	case types.AccessListTxType:
+		var data struct {
+			Value      *big.Int
+			GasPrice   *big.Int
+			Data       []byte
+			AccessList types.AccessList
+		}
+		err = s.Decode(&data)
+		value, tx.GasPrice, tx.Data, tx.AccessList = data.Value, (*hexutil.Big)(data.GasPrice), data.Data, data.AccessList
+	case types.DynamicFeeTxType:
+		var data struct {
+			Value                *big.Int
+			MaxPriorityFeePerGas *big.Int
+			MaxFeePerGas         *big.Int
+			Data                 []byte
+			AccessList           types.AccessList
+		}
+		err = s.Decode(&data)
+		value, tx.Data, tx.AccessList = data.Value, data.Data, data.AccessList
+		tx.MaxPriorityFeePerGas, tx.MaxFeePerGas = (*hexutil.Big)(data.MaxPriorityFeePerGas), (*hexutil.Big)(data.MaxFeePerGas)
+	case setCodeTxType:
+		var data struct {
+			Value                *big.Int
+			MaxPriorityFeePerGas *big.Int
+			MaxFeePerGas         *big.Int
+			Data                 []byte
+			AccessList           types.AccessList
+			AuthorizationList    rlp.RawValue
+		}
+		err = s.Decode(&data)
+		value, tx.Data, tx.AccessList, tx.AuthorizationList = data.Value, data.Data, data.AccessList, []byte(data.AuthorizationList)
+		tx.MaxPriorityFeePerGas, tx.MaxFeePerGas = (*hexutil.Big)(data.MaxPriorityFeePerGas), (*hexutil.Big)(data.MaxFeePerGas)
+	default:
+		return fmt.Errorf("unsupported transaction type %d", typ)
+	}
+	if err != nil {
+		return err
+	}
+	tx.Value = (*hexutil.Big)(value)
+	return nil
+}
+
+// setCodeTxType is the EIP-7702 transaction type, allowed in span batches since the Isthmus upgrade
+const setCodeTxType = 0x04
+
+func readSpanBatchCount(r *bytes.Reader, name string) (uint64, error) {
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid %s", err, name)
+	}
+	if count > maxSpanBatchElementCount {
+		return 0, fmt.Errorf("%s %d is too large", name, count)
+	}
+	return count, nil
+}
+
+// readBitlist reads a bitlist of n bits, stored as a big endian integer padded to a whole number of bytes
+func readBitlist(r *bytes.Reader, n uint64) (*big.Int, error) {
+	buf := make([]byte, (n+7)/8)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	bits := new(big.Int).SetBytes(buf)
+	if uint64(bits.BitLen()) > n {
+		return nil, errors.New("bitlist has bits set past its length")
+	}
+	return bits, nil
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/andybalholm/brotli"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// derivationVersion0 prefixes the frames of batcher transactions and blobs
+	derivationVersion0 = 0x00
+	// opFrameOverhead is the size of a frame excluding its data
+	opFrameOverhead = 16 + 2 + 4 + 1
+	// maxOPFrameSize bounds the frame data length, as frames are never larger than a blob
+	maxOPFrameSize = 1_000_000
+	// maxRLPBytesPerChannel bounds the size of a decompressed channel (post-Fjord value)
+	maxRLPBytesPerChannel = 100_000_000
+	// channelVersionBrotli prefixes brotli compressed channels
+	channelVersionBrotli = 0x01
+)
+
+type opChannelID [16]byte
+
+func (id opChannelID) String() string {
+	return hexutil.Encode(id[:])
+}
+
+func (id opChannelID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// opFrame is a chunk of an OP Stack channel posted by the batcher
+type opFrame struct {
+	ChannelID   opChannelID
+	FrameNumber uint16
+	Data        []byte
+	IsLast      bool
+}
+
+// parseOPFrames parses the frames of a batcher transaction or blob payload
+func parseOPFrames(data []byte) ([]opFrame, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty frame data")
+	}
+	if data[0] != derivationVersion0 {
+		return nil, fmt.Errorf("unsupported derivation version %d", data[0])
+	}
+	data = data[1:]
+
+	var frames []opFrame
+	for len(data) != 0 {
+		if len(data) < opFrameOverhead {
+			return nil, fmt.Errorf("frame %d is truncated", len(frames))
+		}
+		var f opFrame
+		copy(f.ChannelID[:], data[:16])
+		f.FrameNumber = binary.BigEndian.Uint16(data[16:18])
+		length := binary.BigEndian.Uint32(data[18:22])
+		if length > maxOPFrameSize || int(length) > len(data)-opFrameOverhead {
+			return nil, fmt.Errorf("invalid length %d of frame %d", length, len(frames))
+		}
+		f.Data = data[22 : 22+length]
+		switch data[22+length] {
+		case 0:
+		case 1:
+			f.IsLast = true
+		default:
+			return nil, fmt.Errorf("invalid is_last byte of frame %d", len(frames))
+		}
+		data = data[opFrameOverhead+length:]
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+// opChannel collects the frames of a channel until it's complete
+type opChannel struct {
+	frames map[uint16]opFrame
+	// last is the number of the last frame, if it has been seen
+	last *uint16
+}
+
+func (c *opChannel) add(f opFrame) error {
+	if _, ok := c.frames[f.FrameNumber]; ok {
+		return fmt.Errorf("duplicate frame %d", f.FrameNumber)
+	}
+	if c.last != nil && f.FrameNumber > *c.last {
+		return fmt.Errorf("frame %d is past the last frame %d", f.FrameNumber, *c.last)
+	}
+	if f.IsLast {
+		if c.last != nil {
+			return fmt.Errorf("channel is already closed by frame %d", *c.last)
+		}
+		for number := range c.frames {
+			if number > f.FrameNumber {
+				return fmt.Errorf("last frame %d is before frame %d", f.FrameNumber, number)
+			}
+		}
+		last := f.FrameNumber
+		c.last = &last
+	}
+	c.frames[f.FrameNumber] = f
+	return nil
+}
+
+func (c *opChannel) complete() bool {
+	return c.last != nil && len(c.frames) == int(*c.last)+1
+}
+
+// data concatenates the data of the frames of a complete channel
+func (c *opChannel) data() []byte {
+	var data []byte
+	for i := 0; i <= int(*c.last); i++ {
+		data = append(data, c.frames[uint16(i)].Data...)
+	}
+	return data
+}
+
+// opFrameDecoder reassembles the channels of OP Stack frames spread across blobs and prints the batches of every
+// completed channel as JSON
+type opFrameDecoder struct {
+	out      *json.Encoder
+	channels map[opChannelID]*opChannel
+}
+
+func newOPFrameDecoder(out io.Writer) *opFrameDecoder {
+	return &opFrameDecoder{
+		out:      json.NewEncoder(out),
+		channels: make(map[opChannelID]*opChannel),
+	}
+}
+
+func (d *opFrameDecoder) Codec() BlobCodec {
+	return opCodec{}
+}
+
+// AddBlob consumes the frames of a decoded blob
+func (d *opFrameDecoder) AddBlob(data []byte) error {
+	frames, err := parseOPFrames(data)
+	if err != nil {
+		return err
+	}
+	for _, f := range frames {
+		c, ok := d.channels[f.ChannelID]
+		if !ok {
+			c = &opChannel{frames: make(map[uint16]opFrame)}
+			d.channels[f.ChannelID] = c
+		}
+		if err := c.add(f); err != nil {
+			log.Printf("dropping invalid channel. channel=%v err=%v", f.ChannelID, err)
+			delete(d.channels, f.ChannelID)
+			continue
+		}
+		if !c.complete() {
+			continue
+		}
+		delete(d.channels, f.ChannelID)
+		if err := d.emit(f.ChannelID, c.data()); err != nil {
+			log.Printf("unable to decode channel. channel=%v err=%v", f.ChannelID, err)
+		}
+	}
+	return nil
+}
+
+// Flush reports the channels that are still missing frames
+func (d *opFrameDecoder) Flush() error {
+	for id, c := range d.channels {
+		log.Printf("incomplete channel. channel=%v frames=%d closed=%v", id, len(c.frames), c.last != nil)
+	}
+	return nil
+}
+
+func (d *opFrameDecoder) emit(id opChannelID, data []byte) error {
+	r, err := decompressChannel(data)
+	if err != nil {
+		return err
+	}
+	s := rlp.NewStream(r, maxRLPBytesPerChannel)
+	for i := 0; ; i++ {
+		b, err := s.Bytes()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%w: unable to read batch %d", err, i)
+		}
+		batch, err := decodeOPBatch(b)
+		if err != nil {
+			return fmt.Errorf("%w: unable to decode batch %d", err, i)
+		}
+		if err := d.out.Encode(opChannelBatch{ChannelID: id, Index: i, Batch: batch}); err != nil {
+			return err
+		}
+	}
+}
+
+type opChannelBatch struct {
+	ChannelID opChannelID `json:"channel_id"`
+	Index     int         `json:"index"`
+	Batch     any         `json:"batch"`
+}
+
+// decompressChannel detects the compression of the channel from its first byte: zlib streams start with a CM of 8 or
+// 15 in the lower nibble, brotli compressed channels are prefixed with a version byte of 1
+func decompressChannel(data []byte) (io.Reader, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty channel")
+	}
+	var r io.Reader
+	switch {
+	case data[0]&0x0f == 8 || data[0]&0x0f == 15:
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid zlib stream", err)
+		}
+		r = zr
+	case data[0] == channelVersionBrotli:
+		r = brotli.NewReader(bytes.NewReader(data[1:]))
+	default:
+		return nil, fmt.Errorf("unsupported channel compression %#x", data[0])
+	}
+	return io.LimitReader(r, maxRLPBytesPerChannel), nil
+}
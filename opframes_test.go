@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func encodeOPFrame(id opChannelID, number uint16, data []byte, last bool) []byte {
+	b := append([]byte{}, id[:]...)
+	b = binary.BigEndian.AppendUint16(b, number)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+	b = append(b, data...)
+	if last {
+		return append(b, 1)
+	}
+	return append(b, 0)
+}
+
+func testSpanBatch(t *testing.T) []byte {
+	b := binary.AppendUvarint(nil, 10)            // rel_timestamp
+	b = binary.AppendUvarint(b, 5)                // l1_origin_num
+	b = append(b, make([]byte, 40)...)            // parent_check and l1_origin_check
+	b = binary.AppendUvarint(b, 2)                // block_count
+	b = append(b, 0b10)                           // origin_bits
+	b = append(b, 1, 0)                           // block_tx_counts
+	b = append(b, 0b0, 0b1)                       // contract_creation_bits and y_parity_bits
+	b = append(b, bytes.Repeat([]byte{1}, 64)...) // tx_sigs
+	b = append(b, common.HexToAddress("0xdead").Bytes()...)
+	txData, err := rlp.EncodeToBytes([]any{big.NewInt(1), big.NewInt(2), big.NewInt(3), []byte{0xab}, []any{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b = append(b, 0x02)
+	b = append(b, txData...)
+	b = binary.AppendUvarint(b, 7)     // tx_nonces
+	b = binary.AppendUvarint(b, 21000) // tx_gases
+	return append([]byte{spanBatchType}, b...)
+}
+
+func TestOPFrameDecoder(t *testing.T) {
+	singular, err := rlp.EncodeToBytes(&singularBatch{EpochNum: 3, Timestamp: 4, Transactions: nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batches, err := rlp.EncodeToBytes([][]byte{append([]byte{singularBatchType}, singular...), testSpanBatch(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the channel is an RLP stream of batches rather than an RLP list
+	_, content, _, err := rlp.Split(batches)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var zlibChannel bytes.Buffer
+	zw := zlib.NewWriter(&zlibChannel)
+	zw.Write(content)
+	zw.Close()
+	brotliChannel := bytes.NewBuffer([]byte{channelVersionBrotli})
+	bw := brotli.NewWriter(brotliChannel)
+	bw.Write(content)
+	bw.Close()
+
+	for name, channel := range map[string][]byte{"zlib": zlibChannel.Bytes(), "brotli": brotliChannel.Bytes()} {
+		var out bytes.Buffer
+		d := newOPFrameDecoder(&out)
+		id := opChannelID{1, 2, 3}
+		half := len(channel) / 2
+		// frames arrive out of order in separate blobs
+		if err := d.AddBlob(append([]byte{derivationVersion0}, encodeOPFrame(id, 1, channel[half:], true)...)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if out.Len() != 0 {
+			t.Fatalf("%s: channel decoded before it was complete", name)
+		}
+		if err := d.AddBlob(append([]byte{derivationVersion0}, encodeOPFrame(id, 0, channel[:half], false)...)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(d.channels) != 0 {
+			t.Fatalf("%s: channel was not completed", name)
+		}
+
+		dec := json.NewDecoder(&out)
+		var first struct {
+			Batch singularBatch `json:"batch"`
+		}
+		if err := dec.Decode(&first); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if first.Batch.Type != "singular" || first.Batch.EpochNum != 3 || first.Batch.Timestamp != 4 {
+			t.Fatalf("%s: unexpected singular batch %+v", name, first.Batch)
+		}
+		var second struct {
+			Batch spanBatch `json:"batch"`
+		}
+		if err := dec.Decode(&second); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		span := second.Batch
+		if span.Type != "span" || len(span.Blocks) != 2 || span.Blocks[0].EpochNum != 4 || span.Blocks[1].EpochNum != 5 {
+			t.Fatalf("%s: unexpected span batch %+v", name, span)
+		}
+		tx := span.Blocks[0].Transactions[0]
+		if tx.Type != 2 || *tx.To != common.HexToAddress("0xdead") || tx.Nonce != 7 || tx.Gas != 21000 || tx.YParity != 1 ||
+			tx.MaxFeePerGas.ToInt().Int64() != 3 || !bytes.Equal(tx.Data, []byte{0xab}) {
+			t.Fatalf("%s: unexpected span batch tx %+v", name, tx)
+		}
+	}
+}
@@ -0,0 +1,436 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang/snappy"
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/libp2p/go-libp2p/core/sec"
+	"github.com/libp2p/go-libp2p/p2p/transport/tcp"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	rpcProtocolPrefix = "/eth2/beacon_chain/req/"
+	rpcEncodingSuffix = "/ssz_snappy"
+
+	rpcStatusTopicV1              = "status/1"
+	rpcGoodbyeTopicV1             = "goodbye/1"
+	rpcPingTopicV1                = "ping/1"
+	rpcMetaDataTopicV1            = "metadata/1"
+	rpcMetaDataTopicV2            = "metadata/2"
+	rpcMetaDataTopicV3            = "metadata/3"
+	rpcBlocksByRangeTopicV2       = "beacon_blocks_by_range/2"
+	rpcBlocksByRootTopicV2        = "beacon_blocks_by_root/2"
+	rpcBlobSidecarsByRangeTopicV1 = "blob_sidecars_by_range/1"
+	rpcBlobSidecarsByRootTopicV1  = "blob_sidecars_by_root/1"
+
+	responseCodeSuccess             = byte(0x00)
+	responseCodeResourceUnavailable = byte(0x03)
+
+	// maxPayloadSize is the MAX_PAYLOAD_SIZE limit of a single encoded payload
+	maxPayloadSize = 10 << 20
+	// maxErrorMessageSize is the limit of the ErrorMessage returned with non-success response codes
+	maxErrorMessageSize = 256
+	// maxBlobsPerBlock is MAX_BLOBS_PER_BLOCK_ELECTRA, used to request every blob of a block by root
+	maxBlobsPerBlock = 9
+	// maxRequestBlocks is MAX_REQUEST_BLOCKS_DENEB, the limit of the count of a blob_sidecars_by_range request
+	maxRequestBlocks = 128
+
+	statusSSZSize = 4 + 32 + 8 + 32 + 8
+
+	rpcTimeout = 10 * time.Second
+	// statusWait is how long to wait for the peer to send its status before sending ours
+	statusWait = 5 * time.Second
+)
+
+func rpcProtocolID(topic string) protocol.ID {
+	return protocol.ID(rpcProtocolPrefix + topic + rpcEncodingSuffix)
+}
+
+// status is the Status handshake message
+type status struct {
+	ForkDigest     [4]byte
+	FinalizedRoot  common.Hash
+	FinalizedEpoch uint64
+	HeadRoot       common.Hash
+	HeadSlot       uint64
+}
+
+func (s *status) MarshalSSZ() []byte {
+	b := make([]byte, 0, statusSSZSize)
+	b = append(b, s.ForkDigest[:]...)
+	b = append(b, s.FinalizedRoot[:]...)
+	b = binary.LittleEndian.AppendUint64(b, s.FinalizedEpoch)
+	b = append(b, s.HeadRoot[:]...)
+	b = binary.LittleEndian.AppendUint64(b, s.HeadSlot)
+	return b
+}
+
+func (s *status) UnmarshalSSZ(b []byte) error {
+	if len(b) != statusSSZSize {
+		return fmt.Errorf("invalid status size %d", len(b))
+	}
+	copy(s.ForkDigest[:], b[0:4])
+	s.FinalizedRoot = common.BytesToHash(b[4:36])
+	s.FinalizedEpoch = binary.LittleEndian.Uint64(b[36:44])
+	s.HeadRoot = common.BytesToHash(b[44:76])
+	s.HeadSlot = binary.LittleEndian.Uint64(b[76:84])
+	return nil
+}
+
+// writeChunk writes an ssz_snappy encoded payload: the uvarint length of the SSZ bytes followed by the snappy framed
+// SSZ bytes
+func writeChunk(w io.Writer, ssz []byte) error {
+	var size [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(size[:], uint64(len(ssz)))
+	if _, err := w.Write(size[:n]); err != nil {
+		return err
+	}
+	sw := snappy.NewBufferedWriter(w)
+	if _, err := sw.Write(ssz); err != nil {
+		return err
+	}
+	return sw.Close()
+}
+
+// readChunk reads an ssz_snappy encoded payload of at most maxSize SSZ bytes
+func readChunk(r *bufio.Reader, maxSize uint64) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxSize {
+		return nil, fmt.Errorf("payload of %d bytes exceeds limit of %d", size, maxSize)
+	}
+	ssz := make([]byte, size)
+	if _, err := io.ReadFull(snappy.NewReader(r), ssz); err != nil {
+		return nil, fmt.Errorf("%w: unable to read snappy payload", err)
+	}
+	return ssz, nil
+}
+
+// readErrorMessage reads the ErrorMessage following a non-success response code
+func readErrorMessage(r *bufio.Reader, code byte) error {
+	msg, err := readChunk(r, maxErrorMessageSize)
+	if err != nil {
+		return fmt.Errorf("%w: peer returned response code %d", err, code)
+	}
+	return fmt.Errorf("peer returned response code %d: %s", code, msg)
+}
+
+// beaconPeer is a libp2p connection to a beacon node that serves blob sidecars over the req/resp protocols
+type beaconPeer struct {
+	host host.Host
+	id   peer.ID
+
+	// status is the status the peer returned in the handshake
+	status *status
+	// forks is the fork schedule of the chain, used to check the context bytes of blob sidecar responses against the
+	// fork of each sidecar's slot
+	forks *forkSchedule
+	// forkDigests are the accepted context bytes of blob sidecar responses if the fork schedule is unknown
+	forkDigests map[[4]byte]bool
+	// peerStatus receives the status requests of the peer
+	peerStatus chan *status
+}
+
+// dialBeaconPeer connects to the beacon node at addr and performs the status handshake. The fork digest of our status
+// is taken from the status the peer sends us, falling back to forkDigest if the peer doesn't send one in time.
+func dialBeaconPeer(ctx context.Context, addr string, forkDigest []byte) (*beaconPeer, error) {
+	key, _, err := crypto.GenerateSecp256k1Key(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	h, err := libp2p.New(libp2p.Identity(key), libp2p.Transport(tcp.NewTCPTransport), libp2p.NoListenAddrs)
+	if err != nil {
+		return nil, err
+	}
+	p := &beaconPeer{
+		host:        h,
+		forkDigests: make(map[[4]byte]bool),
+		peerStatus:  make(chan *status, 1),
+	}
+	// setup enough handlers so we look like a beacon peer
+	// Some clients, including lighthouse, expect a minimum set of protocols before completing
+	// a libp2p connection
+	p.setHandlers()
+
+	multiaddr, err := getMultiaddr(ctx, h, addr)
+	if err != nil {
+		h.Close()
+		return nil, fmt.Errorf("%w: unable to get multiaddr", err)
+	}
+	addrInfo, err := peer.AddrInfoFromP2pAddr(multiaddr)
+	if err != nil {
+		h.Close()
+		return nil, fmt.Errorf("%w: unable to get addr info", err)
+	}
+	if err := h.Connect(ctx, *addrInfo); err != nil {
+		h.Close()
+		return nil, fmt.Errorf("%w: failed to connect", err)
+	}
+	p.id = addrInfo.ID
+
+	var ours *status
+	select {
+	case s := <-p.peerStatus:
+		ours = s
+	case <-time.After(statusWait):
+		if len(forkDigest) != 4 {
+			h.Close()
+			return nil, errors.New("peer did not send its status, a fork digest is required")
+		}
+		ours = new(status)
+		copy(ours.ForkDigest[:], forkDigest)
+	}
+	if p.status, err = p.requestStatus(ctx, ours); err != nil {
+		h.Close()
+		return nil, fmt.Errorf("%w: status handshake failed", err)
+	}
+	p.forkDigests[p.status.ForkDigest] = true
+	if len(forkDigest) == 4 {
+		var digest [4]byte
+		copy(digest[:], forkDigest)
+		p.forkDigests[digest] = true
+	}
+	return p, nil
+}
+
+func (p *beaconPeer) Close() error {
+	return p.host.Close()
+}
+
+// BlobSidecars fetches the blob sidecars of a block. The block ID may be a slot or a block root, or "head" and
+// "finalized" to use the roots of the peer's status.
+func (p *beaconPeer) BlobSidecars(ctx context.Context, blockID string, indices ...uint64) ([]*BlobSidecar, error) {
+	if slot, err := strconv.ParseUint(blockID, 10, 64); err == nil {
+		sidecars, err := p.BlobSidecarsByRange(ctx, slot, 1)
+		if err != nil || len(indices) == 0 {
+			return sidecars, err
+		}
+		var filtered []*BlobSidecar
+		for _, sidecar := range sidecars {
+			for _, index := range indices {
+				if sidecar.Index == index {
+					filtered = append(filtered, sidecar)
+				}
+			}
+		}
+		return filtered, nil
+	}
+	root, err := p.BlockRoot(ctx, blockID)
+	if err != nil {
+		return nil, err
+	}
+	if len(indices) == 0 {
+		for i := uint64(0); i < maxBlobsPerBlock; i++ {
+			indices = append(indices, i)
+		}
+	}
+	return p.BlobSidecarsByRoot(ctx, root, indices)
+}
+
+// BlockRoot returns the root of a block ID, which may be a block root, or "head" and "finalized" to use the roots of
+// the peer's status
+func (p *beaconPeer) BlockRoot(ctx context.Context, blockID string) (common.Hash, error) {
+	switch {
+	case blockID == "head":
+		return p.status.HeadRoot, nil
+	case blockID == "finalized":
+		return p.status.FinalizedRoot, nil
+	case strings.HasPrefix(blockID, "0x"):
+		return common.HexToHash(blockID), nil
+	}
+	return common.Hash{}, fmt.Errorf("unsupported block id %s", blockID)
+}
+
+// BlobSidecarsByRange requests the blob sidecars of count slots starting at startSlot
+func (p *beaconPeer) BlobSidecarsByRange(ctx context.Context, startSlot, count uint64) ([]*BlobSidecar, error) {
+	if count > maxRequestBlocks {
+		return nil, fmt.Errorf("count %d exceeds limit of %d", count, maxRequestBlocks)
+	}
+	req := binary.LittleEndian.AppendUint64(nil, startSlot)
+	req = binary.LittleEndian.AppendUint64(req, count)
+	return p.requestBlobSidecars(ctx, rpcBlobSidecarsByRangeTopicV1, req, count*maxBlobsPerBlock)
+}
+
+// BlobSidecarsByRoot requests the blob sidecars at the given indices of a block
+func (p *beaconPeer) BlobSidecarsByRoot(ctx context.Context, root common.Hash, indices []uint64) ([]*BlobSidecar, error) {
+	var req []byte
+	for _, index := range indices {
+		req = append(req, root[:]...)
+		req = binary.LittleEndian.AppendUint64(req, index)
+	}
+	return p.requestBlobSidecars(ctx, rpcBlobSidecarsByRootTopicV1, req, uint64(len(indices)))
+}
+
+func (p *beaconPeer) requestBlobSidecars(ctx context.Context, topic string, req []byte, maxChunks uint64) ([]*BlobSidecar, error) {
+	stream, err := p.request(ctx, topic, req)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var sidecars []*BlobSidecar
+	r := bufio.NewReader(stream)
+	for {
+		_ = stream.SetReadDeadline(time.Now().Add(rpcTimeout))
+		code, err := r.ReadByte()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if code != responseCodeSuccess {
+			return nil, readErrorMessage(r, code)
+		}
+		if uint64(len(sidecars)) == maxChunks {
+			return nil, fmt.Errorf("peer returned more than %d blob sidecars", maxChunks)
+		}
+		var digest [4]byte
+		if _, err := io.ReadFull(r, digest[:]); err != nil {
+			return nil, fmt.Errorf("%w: unable to read context bytes", err)
+		}
+		ssz, err := readChunk(r, uint64(blobSidecarSSZSize))
+		if err != nil {
+			return nil, err
+		}
+		sidecar := new(BlobSidecar)
+		if err := sidecar.UnmarshalSSZ(ssz); err != nil {
+			return nil, err
+		}
+		if p.forks != nil && p.forks.digest(sidecar.Slot()) != digest {
+			return nil, fmt.Errorf("unexpected fork digest %x of slot %d in response", digest, sidecar.Slot())
+		} else if p.forks == nil && !p.forkDigests[digest] {
+			return nil, fmt.Errorf("unexpected fork digest %x in response", digest)
+		}
+		sidecars = append(sidecars, sidecar)
+	}
+	return sidecars, nil
+}
+
+// forkSchedule computes the fork digests of the forks of a chain. Blob sidecar responses are prefixed with the digest
+// of the fork of the sidecar's slot, so a range spanning a fork boundary carries more than one digest.
+type forkSchedule struct {
+	genesisValidatorsRoot common.Hash
+	slotsPerEpoch         uint64
+	// forks are the fork versions in the order of their activation epoch
+	forks []forkVersion
+}
+
+type forkVersion struct {
+	Epoch   uint64
+	Version [4]byte
+}
+
+// digest returns the fork digest of the fork active at slot, the first 4 bytes of the hash tree root of the fork data.
+// The digests of the blob parameter only forks of fulu are not supported, as blob sidecars are no longer served then.
+func (s *forkSchedule) digest(slot uint64) [4]byte {
+	var version [32]byte
+	for _, fork := range s.forks {
+		if fork.Epoch > slot/s.slotsPerEpoch {
+			break
+		}
+		copy(version[:], fork.Version[:])
+	}
+	root := hashPair(version, s.genesisValidatorsRoot)
+	var digest [4]byte
+	copy(digest[:], root[:4])
+	return digest
+}
+
+func (p *beaconPeer) requestStatus(ctx context.Context, ours *status) (*status, error) {
+	stream, err := p.request(ctx, rpcStatusTopicV1, ours.MarshalSSZ())
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	_ = stream.SetReadDeadline(time.Now().Add(rpcTimeout))
+	r := bufio.NewReader(stream)
+	code, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if code != responseCodeSuccess {
+		return nil, readErrorMessage(r, code)
+	}
+	ssz, err := readChunk(r, statusSSZSize)
+	if err != nil {
+		return nil, err
+	}
+	theirs := new(status)
+	if err := theirs.UnmarshalSSZ(ssz); err != nil {
+		return nil, err
+	}
+	return theirs, nil
+}
+
+// request opens a stream for the topic and writes the request. The caller reads the response and closes the stream.
+func (p *beaconPeer) request(ctx context.Context, topic string, req []byte) (network.Stream, error) {
+	stream, err := p.host.NewStream(ctx, p.id, rpcProtocolID(topic))
+	if err != nil {
+		return nil, err
+	}
+	_ = stream.SetWriteDeadline(time.Now().Add(rpcTimeout))
+	if err := writeChunk(stream, req); err != nil {
+		_ = stream.Reset()
+		return nil, err
+	}
+	if err := stream.CloseWrite(); err != nil {
+		_ = stream.Reset()
+		return nil, err
+	}
+	return stream, nil
+}
+
+func getMultiaddr(ctx context.Context, h host.Host, addr string) (ma.Multiaddr, error) {
+	multiaddr, err := ma.NewMultiaddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	_, id := peer.SplitAddr(multiaddr)
+	if id != "" {
+		return multiaddr, nil
+	}
+	// peer ID wasn't provided, look it up
+	id, err = retrievePeerID(ctx, h, addr)
+	if err != nil {
+		return nil, err
+	}
+	return ma.NewMultiaddr(fmt.Sprintf("%s/p2p/%s", addr, id))
+}
+
+// Helper for retrieving the peer ID from a security error... obviously don't use this in production!
+func retrievePeerID(ctx context.Context, h host.Host, addr string) (peer.ID, error) {
+	incorrectPeerID := "16Uiu2HAmSifdT5QutTsaET8xqjWAMPp4obrQv7LN79f2RMmBe3nY"
+	addrInfo, err := peer.AddrInfoFromString(fmt.Sprintf("%s/p2p/%s", addr, incorrectPeerID))
+	if err != nil {
+		return "", err
+	}
+	err = h.Connect(ctx, *addrInfo)
+	if err == nil {
+		return "", errors.New("unexpected successful connection")
+	}
+	var mismatch sec.ErrPeerIDMismatch
+	if errors.As(err, &mismatch) {
+		return mismatch.Actual, nil
+	}
+	return "", err
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestChunk(t *testing.T) {
+	large := make([]byte, blobSidecarSSZSize)
+	for i := range large {
+		large[i] = byte(i % 251)
+	}
+	tests := []struct {
+		name    string
+		payload []byte
+		maxSize uint64
+	}{
+		{name: "empty", payload: []byte{}, maxSize: 8},
+		{name: "ping", payload: []byte{1, 2, 3, 4, 5, 6, 7, 8}, maxSize: 8},
+		{name: "blob sidecar", payload: large, maxSize: uint64(blobSidecarSSZSize)},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := writeChunk(&buf, tt.payload); err != nil {
+			t.Fatal(err)
+		}
+		chunkSize := buf.Len()
+		// a second chunk follows on the same stream
+		if err := writeChunk(&buf, []byte{9}); err != nil {
+			t.Fatal(err)
+		}
+		encoded := buf.Bytes()
+
+		r := bufio.NewReader(bytes.NewReader(encoded))
+		payload, err := readChunk(r, tt.maxSize)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !bytes.Equal(payload, tt.payload) {
+			t.Fatalf("%s: unexpected payload", tt.name)
+		}
+		if payload, err := readChunk(r, 1); err != nil || !bytes.Equal(payload, []byte{9}) {
+			t.Fatalf("%s: unexpected second payload %x: %v", tt.name, payload, err)
+		}
+		if _, err := readChunk(r, 1); !errors.Is(err, io.EOF) {
+			t.Fatalf("%s: expected end of stream, got %v", tt.name, err)
+		}
+
+		if len(tt.payload) > 0 {
+			if _, err := readChunk(bufio.NewReader(bytes.NewReader(encoded)), uint64(len(tt.payload)-1)); err == nil {
+				t.Fatalf("%s: expected payload exceeding the limit to be rejected", tt.name)
+			}
+			if _, err := readChunk(bufio.NewReader(bytes.NewReader(encoded[:chunkSize-1])), tt.maxSize); err == nil {
+				t.Fatalf("%s: expected truncated payload to be rejected", tt.name)
+			}
+		}
+	}
+
+	// the size prefix doesn't match the framed payload
+	var buf bytes.Buffer
+	if err := writeChunk(&buf, []byte{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+	encoded[0] = 4
+	if _, err := readChunk(bufio.NewReader(bytes.NewReader(encoded)), 8); err == nil {
+		t.Fatal("expected short payload to be rejected")
+	}
+}
+
+func TestReadErrorMessage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeChunk(&buf, []byte("not serving blobs")); err != nil {
+		t.Fatal(err)
+	}
+	err := readErrorMessage(bufio.NewReader(&buf), responseCodeResourceUnavailable)
+	if err == nil || err.Error() != "peer returned response code 3: not serving blobs" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestStatusSSZ(t *testing.T) {
+	s := &status{ForkDigest: [4]byte{1, 2, 3, 4}, FinalizedEpoch: 5, HeadSlot: 6}
+	s.FinalizedRoot[0], s.HeadRoot[31] = 7, 8
+	enc := s.MarshalSSZ()
+	if len(enc) != statusSSZSize {
+		t.Fatalf("unexpected status size %d", len(enc))
+	}
+	decoded := new(status)
+	if err := decoded.UnmarshalSSZ(enc); err != nil {
+		t.Fatal(err)
+	}
+	if *decoded != *s {
+		t.Fatalf("unexpected decoded status %+v", decoded)
+	}
+	if err := decoded.UnmarshalSSZ(enc[1:]); err == nil {
+		t.Fatal("expected invalid status size")
+	}
+}
+
+func TestForkScheduleDigest(t *testing.T) {
+	// the mainnet fork schedule
+	srv := newTestBeaconServer(t, map[string]interface{}{
+		"/eth/v1/config/fork_schedule": map[string]interface{}{"data": []interface{}{
+			map[string]string{"previous_version": "0x03000000", "current_version": "0x04000000", "epoch": "269568"},
+			map[string]string{"previous_version": "0x00000000", "current_version": "0x00000000", "epoch": "0"},
+			map[string]string{"previous_version": "0x00000000", "current_version": "0x01000000", "epoch": "74240"},
+			map[string]string{"previous_version": "0x01000000", "current_version": "0x02000000", "epoch": "144896"},
+			map[string]string{"previous_version": "0x02000000", "current_version": "0x03000000", "epoch": "194048"},
+		}},
+		"/eth/v1/beacon/genesis": map[string]interface{}{"data": map[string]string{
+			"genesis_validators_root": "0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95",
+		}},
+		"/eth/v1/config/spec": map[string]interface{}{"data": map[string]string{"SLOTS_PER_EPOCH": "32"}},
+	})
+	schedule, err := newBeaconClient(srv.URL).ForkSchedule(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		slot   uint64
+		digest string
+	}{
+		{0, "b5303f2a"},
+		{74240*32 - 1, "b5303f2a"},
+		{74240 * 32, "afcaaba0"},
+		{144896 * 32, "4a26c58b"},
+		{194048*32 + 31, "bba4da96"},
+		{269568*32 - 1, "bba4da96"},
+		{269568 * 32, "6a95a1a9"},
+		{math.MaxUint64, "6a95a1a9"},
+	}
+	for _, tt := range tests {
+		if digest := schedule.digest(tt.slot); hex.EncodeToString(digest[:]) != tt.digest {
+			t.Fatalf("slot %d: expected fork digest %s, got %x", tt.slot, tt.digest, digest)
+		}
+	}
+
+	srv = newTestBeaconServer(t, nil)
+	if _, err := newBeaconClient(srv.URL).ForkSchedule(context.Background()); err == nil {
+		t.Fatal("expected missing fork schedule")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// pointEvaluationInputLength is the length of the input of the point evaluation precompile at 0x0A
+const pointEvaluationInputLength = 192
+
+// pointEvaluationInput is the input of the point evaluation precompile
+type pointEvaluationInput struct {
+	VersionedHash common.Hash
+	Z             kzg4844.Point
+	Y             kzg4844.Claim
+	Commitment    kzg4844.Commitment
+	Proof         kzg4844.Proof
+}
+
+// Bytes returns the 192 byte precompile input: versioned hash, z, y, commitment and proof
+func (in *pointEvaluationInput) Bytes() []byte {
+	b := make([]byte, 0, pointEvaluationInputLength)
+	b = append(b, in.VersionedHash[:]...)
+	b = append(b, in.Z[:]...)
+	b = append(b, in.Y[:]...)
+	b = append(b, in.Commitment[:]...)
+	return append(b, in.Proof[:]...)
+}
+
+func decodePointEvaluationInput(b []byte) (*pointEvaluationInput, error) {
+	if len(b) != pointEvaluationInputLength {
+		return nil, fmt.Errorf("invalid point evaluation input length %d", len(b))
+	}
+	in := new(pointEvaluationInput)
+	copy(in.VersionedHash[:], b[0:32])
+	copy(in.Z[:], b[32:64])
+	copy(in.Y[:], b[64:96])
+	copy(in.Commitment[:], b[96:144])
+	copy(in.Proof[:], b[144:192])
+	return in, nil
+}
+
+// pointEvaluationOutput returns the output of the precompile on success: FIELD_ELEMENTS_PER_BLOB and BLS_MODULUS as
+// 32 byte big endian integers
+func pointEvaluationOutput() []byte {
+	out := make([]byte, 64)
+	new(big.Int).SetUint64(params.BlobTxFieldElementsPerBlob).FillBytes(out[:32])
+	fr.Modulus().FillBytes(out[32:])
+	return out
+}
+
+var errVersionedHashMismatch = errors.New("versioned hash does not match the commitment")
+
+// verifyPointEvaluation runs the point evaluation precompile of EIP-4844 and returns its output
+func verifyPointEvaluation(input []byte) ([]byte, error) {
+	in, err := decodePointEvaluationInput(input)
+	if err != nil {
+		return nil, err
+	}
+	if kZGToVersionedHash(in.Commitment) != in.VersionedHash {
+		return nil, errVersionedHashMismatch
+	}
+	if err := kzg4844.VerifyProof(in.Commitment, in.Z, in.Y, in.Proof); err != nil {
+		return nil, fmt.Errorf("%w: invalid kzg proof", err)
+	}
+	return pointEvaluationOutput(), nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+func TestVerifyPointEvaluation(t *testing.T) {
+	blobs, commitments, _, versionedHashes, err := EncodeBlobs([]byte("hello blobs"), losslessCodec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := kzg4844.Point{31: 5}
+	proof, y, err := kzg4844.ComputeProof(blobs[0], z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := &pointEvaluationInput{VersionedHash: versionedHashes[0], Z: z, Y: y, Commitment: commitments[0], Proof: proof}
+	input := in.Bytes()
+	decoded, err := decodePointEvaluationInput(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *decoded != *in {
+		t.Fatalf("expected %+v, got %+v", in, decoded)
+	}
+
+	precompile := vm.PrecompiledContractsCancun[common.BytesToAddress([]byte{0x0a})]
+	expected, err := precompile.Run(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := verifyPointEvaluation(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, expected) {
+		t.Fatalf("expected %x, got %x", expected, out)
+	}
+
+	wrongHash := *in
+	wrongHash.VersionedHash[1] ^= 1
+	if _, err := verifyPointEvaluation(wrongHash.Bytes()); !errors.Is(err, errVersionedHashMismatch) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	wrongY := *in
+	wrongY.Y[31] ^= 1
+	if _, err := verifyPointEvaluation(wrongY.Bytes()); err == nil {
+		t.Fatal("expected an invalid evaluation to fail")
+	}
+	if _, err := verifyPointEvaluation(input[:191]); err == nil {
+		t.Fatal("expected a short input to fail")
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// primitiveRootOfUnity is PRIMITIVE_ROOT_OF_UNITY from EIP-4844
+const primitiveRootOfUnity = 7
+
+// rootOfUnity returns a primitive nth root of unity of the BLS scalar field. n must be a power of two.
+func rootOfUnity(n int) fr.Element {
+	exp := new(big.Int).Sub(fr.Modulus(), big.NewInt(1))
+	exp.Div(exp, big.NewInt(int64(n)))
+	var g, w fr.Element
+	g.SetUint64(primitiveRootOfUnity)
+	w.Exp(g, exp)
+	return w
+}
+
+// bitReverse permutes values into bit-reversal order, which is the order of the evaluation domain of blobs. The
+// permutation is its own inverse. The number of values must be a power of two.
+func bitReverse(values []fr.Element) {
+	shift := 64 - bits.TrailingZeros(uint(len(values)))
+	for i := range values {
+		j := int(bits.Reverse64(uint64(i)) >> shift)
+		if i < j {
+			values[i], values[j] = values[j], values[i]
+		}
+	}
+}
+
+// fft evaluates the polynomial with the given coefficients at the powers of the nth root of unity, where n is the
+// number of coefficients. The evaluations replace the coefficients in natural order.
+func fft(values []fr.Element) {
+	fftWithRoot(values, rootOfUnity(len(values)))
+}
+
+// inverseFFT interpolates the polynomial with the given evaluations at the powers of the nth root of unity. The
+// coefficients replace the evaluations.
+func inverseFFT(values []fr.Element) {
+	w := rootOfUnity(len(values))
+	w.Inverse(&w)
+	fftWithRoot(values, w)
+
+	var nInv fr.Element
+	nInv.SetUint64(uint64(len(values)))
+	nInv.Inverse(&nInv)
+	for i := range values {
+		values[i].Mul(&values[i], &nInv)
+	}
+}
+
+// fftWithRoot is an iterative radix-2 Cooley-Tukey transform
+func fftWithRoot(values []fr.Element, w fr.Element) {
+	n := len(values)
+	bitReverse(values)
+	for size := 2; size <= n; size <<= 1 {
+		half := size / 2
+		var step fr.Element
+		step.Exp(w, big.NewInt(int64(n/size)))
+		twiddles := make([]fr.Element, half)
+		twiddles[0].SetOne()
+		for i := 1; i < half; i++ {
+			twiddles[i].Mul(&twiddles[i-1], &step)
+		}
+		for start := 0; start < n; start += size {
+			for j := 0; j < half; j++ {
+				var t fr.Element
+				t.Mul(&values[start+j+half], &twiddles[j])
+				u := values[start+j]
+				values[start+j].Add(&u, &t)
+				values[start+j+half].Sub(&u, &t)
+			}
+		}
+	}
+}
+
+// blobCoefficients interpolates the polynomial of a blob, whose field elements are its evaluations over the
+// bit-reversed domain, and returns its coefficients
+func blobCoefficients(blob []byte) ([]fr.Element, error) {
+	if len(blob) != blobSize {
+		return nil, fmt.Errorf("invalid blob length %d", len(blob))
+	}
+	values := make([]fr.Element, params.BlobTxFieldElementsPerBlob)
+	for i := range values {
+		if err := values[i].SetBytesCanonical(blob[i*32 : (i+1)*32]); err != nil {
+			return nil, fmt.Errorf("%w: invalid field element %d", err, i)
+		}
+	}
+	bitReverse(values)
+	inverseFFT(values)
+	return values, nil
+}
+
+// coefficientsToBlob evaluates the polynomial with the given coefficients over the bit-reversed domain. The
+// coefficients are left untouched.
+func coefficientsToBlob(coefficients []fr.Element) kzg4844.Blob {
+	values := append([]fr.Element{}, coefficients...)
+	fft(values)
+	bitReverse(values)
+
+	var blob kzg4844.Blob
+	for i := range values {
+		b := values[i].Bytes()
+		copy(blob[i*32:], b[:])
+	}
+	return blob
+}
+
+var (
+	blobDomainOnce  sync.Once
+	blobDomainRoots []fr.Element
+)
+
+// blobDomain returns the evaluation domain of blobs: the FIELD_ELEMENTS_PER_BLOB roots of unity in bit-reversal order
+func blobDomain() []fr.Element {
+	blobDomainOnce.Do(func() {
+		w := rootOfUnity(params.BlobTxFieldElementsPerBlob)
+		blobDomainRoots = make([]fr.Element, params.BlobTxFieldElementsPerBlob)
+		blobDomainRoots[0].SetOne()
+		for i := 1; i < len(blobDomainRoots); i++ {
+			blobDomainRoots[i].Mul(&blobDomainRoots[i-1], &w)
+		}
+		bitReverse(blobDomainRoots)
+	})
+	return blobDomainRoots
+}
+
+// evaluateBarycentric evaluates the polynomial with the given evaluations over the domain at z, which may be a point
+// of the domain. This is evaluate_polynomial_in_evaluation_form of the consensus specs.
+func evaluateBarycentric(values, domain []fr.Element, z fr.Element) fr.Element {
+	differences := make([]fr.Element, len(domain))
+	for i := range domain {
+		if domain[i].Equal(&z) {
+			return values[i]
+		}
+		differences[i].Sub(&z, &domain[i])
+	}
+	inverses := fr.BatchInvert(differences)
+
+	// sum(values[i] * domain[i] / (z - domain[i])) * (z^n - 1) / n
+	var sum, term fr.Element
+	for i := range values {
+		term.Mul(&values[i], &domain[i])
+		term.Mul(&term, &inverses[i])
+		sum.Add(&sum, &term)
+	}
+	var zn, one, nInv fr.Element
+	zn.Exp(z, big.NewInt(int64(len(domain))))
+	one.SetOne()
+	zn.Sub(&zn, &one)
+	nInv.SetUint64(uint64(len(domain)))
+	nInv.Inverse(&nInv)
+	sum.Mul(&sum, &zn)
+	return *sum.Mul(&sum, &nInv)
+}
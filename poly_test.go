@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+func TestFFT(t *testing.T) {
+	values := make([]fr.Element, 16)
+	for i := range values {
+		values[i].SetUint64(uint64(i*i + 1))
+	}
+	coefficients := append([]fr.Element{}, values...)
+	fft(values)
+	// the evaluation at the first root of unity, 1, is the sum of the coefficients
+	var sum fr.Element
+	for i := range coefficients {
+		sum.Add(&sum, &coefficients[i])
+	}
+	if !values[0].Equal(&sum) {
+		t.Fatalf("expected %v, got %v", sum, values[0])
+	}
+	inverseFFT(values)
+	for i := range values {
+		if !values[i].Equal(&coefficients[i]) {
+			t.Fatalf("(%d) expected %v, got %v", i, coefficients[i], values[i])
+		}
+	}
+}
+
+func TestEvaluateBlob(t *testing.T) {
+	blobs, err := losslessCodec{}.Encode(makeBlob(4000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := blobs[0]
+	for _, z := range []kzg4844.Point{{31: 2}, {1: 0xab, 31: 0xcd}, computeChallenge(blob, kzg4844.Commitment{})} {
+		_, expected, err := kzg4844.ComputeProof(blob, z)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y, err := EvaluateBlob(blob, z)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if y != expected {
+			t.Fatalf("expected %x at %x, got %x", expected, z, y)
+		}
+	}
+
+	// points of the domain evaluate to the field elements of the blob
+	for _, i := range []int{0, 1, 7, 4095} {
+		y, err := EvaluateBlob(blob, blobDomain()[i].Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if [32]byte(blob[i*32:(i+1)*32]) != y {
+			t.Fatalf("expected field element %d, got %x", i, y)
+		}
+	}
+
+	if _, err := EvaluateBlob(blob, kzg4844.Point{0: 0xff}); err == nil {
+		t.Fatal("expected a non-canonical point to fail")
+	}
+}
+
+func TestBlobCoefficients(t *testing.T) {
+	blobs, err := losslessCodec{}.Encode(makeBlob(4000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	coefficients, err := blobCoefficients(blobs[0][:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if coefficientsToBlob(coefficients) != blobs[0] {
+		t.Fatal("blob does not round trip")
+	}
+
+	// evaluating the coefficients directly matches the barycentric evaluation
+	var z, y fr.Element
+	z.SetUint64(12345)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y.Mul(&y, &z)
+		y.Add(&y, &coefficients[i])
+	}
+	expected, err := EvaluateBlob(blobs[0], z.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y.Bytes() != expected {
+		t.Fatalf("expected %x, got %x", expected, y.Bytes())
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/urfave/cli"
+)
+
+// blobPolynomial is the polynomial of a blob in coefficient form and in evaluation form over the bit-reversed domain,
+// the latter being the blob itself
+type blobPolynomial struct {
+	Coefficients []hexutil.Bytes `json:"coefficients"`
+	Evaluations  []hexutil.Bytes `json:"evaluations"`
+}
+
+func PolynomialApp(cliCtx *cli.Context) error {
+	var (
+		coefficients []fr.Element
+		blob         []byte
+		err          error
+	)
+	switch {
+	case cliCtx.IsSet(PolynomialBlobFileFlag.Name):
+		if blob, err = readBlobFile(cliCtx.String(PolynomialBlobFileFlag.Name)); err != nil {
+			return err
+		}
+		if coefficients, err = blobCoefficients(blob); err != nil {
+			return err
+		}
+	case cliCtx.IsSet(PolynomialCoefficientsFileFlag.Name):
+		if coefficients, err = readCoefficients(cliCtx.String(PolynomialCoefficientsFileFlag.Name)); err != nil {
+			return err
+		}
+		evaluations := coefficientsToBlob(coefficients)
+		blob = evaluations[:]
+	default:
+		return fmt.Errorf("either --%s or --%s is required", PolynomialBlobFileFlag.Name, PolynomialCoefficientsFileFlag.Name)
+	}
+
+	switch format := cliCtx.String(PolynomialOutputFormatFlag.Name); format {
+	case "json":
+		p := &blobPolynomial{}
+		for i := range coefficients {
+			b := coefficients[i].Bytes()
+			p.Coefficients = append(p.Coefficients, b[:])
+			p.Evaluations = append(p.Evaluations, blob[i*32:(i+1)*32])
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(p)
+	case "hex":
+		// only the converted form, so that it can be fed back in
+		out := blob
+		if cliCtx.IsSet(PolynomialBlobFileFlag.Name) {
+			out = make([]byte, 0, blobSize)
+			for i := range coefficients {
+				b := coefficients[i].Bytes()
+				out = append(out, b[:]...)
+			}
+		}
+		_, err := fmt.Println(hexutil.Encode(out))
+		return err
+	default:
+		return fmt.Errorf("unsupported --%s %s", PolynomialOutputFormatFlag.Name, format)
+	}
+}
+
+// readCoefficients reads FIELD_ELEMENTS_PER_BLOB big endian coefficients, either as a JSON array of hex strings or as
+// raw or hex encoded bytes
+func readCoefficients(name string) ([]fr.Element, error) {
+	var data []byte
+	raw, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading coefficients file: %v", err)
+	}
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) != 0 && trimmed[0] == '[' {
+		var list []hexutil.Bytes
+		if err := json.Unmarshal(trimmed, &list); err != nil {
+			return nil, fmt.Errorf("%w: invalid coefficients", err)
+		}
+		for i, c := range list {
+			if len(c) != 32 {
+				return nil, fmt.Errorf("invalid length of coefficient %d", i)
+			}
+			data = append(data, c...)
+		}
+	} else if data, err = readBlobFile(name); err != nil {
+		return nil, err
+	}
+	if len(data) != blobSize {
+		return nil, fmt.Errorf("expected %d coefficients, got %d", params.BlobTxFieldElementsPerBlob, len(data)/32)
+	}
+
+	coefficients := make([]fr.Element, params.BlobTxFieldElementsPerBlob)
+	for i := range coefficients {
+		if err := coefficients[i].SetBytesCanonical(data[i*32 : (i+1)*32]); err != nil {
+			return nil, fmt.Errorf("%w: invalid coefficient %d", err, i)
+		}
+	}
+	return coefficients, nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/urfave/cli"
+)
+
+// opening is an evaluation point of a blob polynomial
+type opening struct {
+	blobIndex int
+	z         kzg4844.Point
+}
+
+// pointProof is a KZG proof of the evaluation of a blob at z
+type pointProof struct {
+	BlobIndex      int           `json:"blob_index"`
+	Z              hexutil.Bytes `json:"z"`
+	Y              hexutil.Bytes `json:"y"`
+	Commitment     hexutil.Bytes `json:"commitment,omitempty"`
+	Proof          hexutil.Bytes `json:"proof,omitempty"`
+	PointEvalInput hexutil.Bytes `json:"pointEvalInput,omitempty"`
+}
+
+// batchProofs opens the blob at --blob-index, or every blob with --all-blobs, at each point of --points-file, at
+// --input-point or at the challenge of the blob, and prints the proofs as a JSON array
+func batchProofs(cliCtx *cli.Context, blobs []kzg4844.Blob, commitments []kzg4844.Commitment, versionedHashes []common.Hash) error {
+	var indices []int
+	if cliCtx.Bool(ProofAllBlobsFlag.Name) {
+		for i := range blobs {
+			indices = append(indices, i)
+		}
+	} else {
+		if !cliCtx.IsSet(ProofBlobIndexFlag.Name) {
+			return fmt.Errorf("either --%s or --%s is required", ProofBlobIndexFlag.Name, ProofAllBlobsFlag.Name)
+		}
+		blobIndex := cliCtx.Uint64(ProofBlobIndexFlag.Name)
+		if blobIndex >= uint64(len(blobs)) {
+			return fmt.Errorf("error reading %d blob", blobIndex)
+		}
+		indices = append(indices, int(blobIndex))
+	}
+
+	var points []kzg4844.Point
+	if cliCtx.Bool(ProofChallengeFlag.Name) {
+		// every blob is opened at its own challenge
+	} else if cliCtx.IsSet(ProofPointsFileFlag.Name) {
+		var err error
+		if points, err = readPoints(cliCtx.String(ProofPointsFileFlag.Name)); err != nil {
+			return err
+		}
+	} else if cliCtx.IsSet(ProofInputPointFlag.Name) {
+		point, err := parsePoint(cliCtx.String(ProofInputPointFlag.Name))
+		if err != nil {
+			return err
+		}
+		points = append(points, point)
+	} else {
+		return fmt.Errorf("either --%s, --%s or --%s is required", ProofInputPointFlag.Name, ProofPointsFileFlag.Name,
+			ProofChallengeFlag.Name)
+	}
+
+	var openings []opening
+	for _, i := range indices {
+		if cliCtx.Bool(ProofChallengeFlag.Name) {
+			openings = append(openings, opening{blobIndex: i, z: computeChallenge(blobs[i], commitments[i])})
+			continue
+		}
+		for _, z := range points {
+			openings = append(openings, opening{blobIndex: i, z: z})
+		}
+	}
+	proofs, err := computePointProofs(blobs, commitments, versionedHashes, openings, cliCtx.Bool(ProofEvalOnlyFlag.Name))
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(proofs)
+}
+
+// computePointProofs computes the proofs of the openings concurrently. The proofs are returned in the order of the
+// openings. With evalOnly, only the evaluations are computed.
+func computePointProofs(blobs []kzg4844.Blob, commitments []kzg4844.Commitment, versionedHashes []common.Hash, openings []opening, evalOnly bool) ([]pointProof, error) {
+	proofs := make([]pointProof, len(openings))
+	errs := make([]error, len(openings))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				o := openings[i]
+				if evalOnly {
+					y, err := EvaluateBlob(blobs[o.blobIndex], o.z)
+					if err != nil {
+						errs[i] = fmt.Errorf("%w: unable to evaluate blob %d at %x", err, o.blobIndex, o.z[:])
+						continue
+					}
+					proofs[i] = pointProof{BlobIndex: o.blobIndex, Z: o.z[:], Y: y[:]}
+					continue
+				}
+				proof, y, err := kzg4844.ComputeProof(blobs[o.blobIndex], o.z)
+				if err != nil {
+					errs[i] = fmt.Errorf("%w: unable to open blob %d at %x", err, o.blobIndex, o.z[:])
+					continue
+				}
+				input := &pointEvaluationInput{
+					VersionedHash: versionedHashes[o.blobIndex],
+					Z:             o.z,
+					Y:             y,
+					Commitment:    commitments[o.blobIndex],
+					Proof:         proof,
+				}
+				proofs[i] = pointProof{
+					BlobIndex:      o.blobIndex,
+					Z:              input.Z[:],
+					Y:              input.Y[:],
+					Commitment:     input.Commitment[:],
+					Proof:          input.Proof[:],
+					PointEvalInput: input.Bytes(),
+				}
+			}
+		}()
+	}
+	for i := range openings {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return proofs, nil
+}
+
+// fiatShamirProtocolDomain is FIAT_SHAMIR_PROTOCOL_DOMAIN from the consensus specs
+var fiatShamirProtocolDomain = []byte("FSBLOBVERIFY_V1_")
+
+// computeChallenge implements compute_challenge from the consensus specs. The blob proof is the proof of the
+// evaluation of the blob at this point.
+func computeChallenge(blob kzg4844.Blob, commitment kzg4844.Commitment) kzg4844.Point {
+	h := sha256.New()
+	h.Write(fiatShamirProtocolDomain)
+	// the degree of the polynomial as a 16 byte big endian integer
+	var degree [16]byte
+	binary.BigEndian.PutUint64(degree[8:], params.BlobTxFieldElementsPerBlob)
+	h.Write(degree[:])
+	h.Write(blob[:])
+	h.Write(commitment[:])
+
+	// hash_to_bls_field reduces the hash modulo BLS_MODULUS
+	var e fr.Element
+	e.SetBytes(h.Sum(nil))
+	return e.Bytes()
+}
+
+// readPoints reads a file of hex encoded evaluation points, one per line. Empty lines and lines starting with # are
+// skipped.
+func readPoints(name string) ([]kzg4844.Point, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading points file: %v", err)
+	}
+	defer f.Close()
+	var points []kzg4844.Point
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		point, err := parsePoint(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d", err, line)
+		}
+		points = append(points, point)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading points file: %v", err)
+	}
+	return points, nil
+}
+
+// parsePoint parses a 32 byte hex encoded evaluation point, with or without 0x prefix
+func parsePoint(s string) (kzg4844.Point, error) {
+	var point kzg4844.Point
+	s = strings.TrimPrefix(s, "0x")
+	if len(s) != 64 {
+		return point, fmt.Errorf("wrong input point, len is %d", len(s))
+	}
+	if err := decodeFixedHex("0x"+s, point[:]); err != nil {
+		return point, fmt.Errorf("%w: invalid input point", err)
+	}
+	return point, nil
+}
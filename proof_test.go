@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+func TestComputePointProofs(t *testing.T) {
+	blobs, err := losslessCodec{}.Encode(makeBlob(losslessBlobCapacity + 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitments, versionedHashes, err := BlobCommitments(blobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(t.TempDir(), "points")
+	points := "# evaluation points\n0x0000000000000000000000000000000000000000000000000000000000000001\n\n" +
+		"00000000000000000000000000000000000000000000000000000000000000ff\n"
+	if err := os.WriteFile(name, []byte(points), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	zs, err := readPoints(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zs) != 2 || zs[0] != (kzg4844.Point{31: 1}) || zs[1] != (kzg4844.Point{31: 0xff}) {
+		t.Fatalf("unexpected points %x", zs)
+	}
+
+	var openings []opening
+	for i := range blobs {
+		for _, z := range zs {
+			openings = append(openings, opening{blobIndex: i, z: z})
+		}
+	}
+	proofs, err := computePointProofs(blobs, commitments, versionedHashes, openings, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proofs) != 4 {
+		t.Fatalf("expected 4 proofs, got %d", len(proofs))
+	}
+	for i, proof := range proofs {
+		if proof.BlobIndex != openings[i].blobIndex || [32]byte(proof.Z) != openings[i].z {
+			t.Fatalf("(%d) proof is out of order", i)
+		}
+		if _, err := verifyPointEvaluation(proof.PointEvalInput); err != nil {
+			t.Fatalf("(%d) %v", i, err)
+		}
+	}
+
+	// points above the modulus can't be opened
+	openings[1].z = kzg4844.Point{0: 0xff}
+	if _, err := computePointProofs(blobs, commitments, versionedHashes, openings, false); err == nil {
+		t.Fatal("expected a non-canonical point to fail")
+	}
+}
+
+func TestComputeChallenge(t *testing.T) {
+	blobs, commitments, blobProofs, versionedHashes, err := EncodeBlobs([]byte("hello blobs"), losslessCodec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := computeChallenge(blobs[0], commitments[0])
+	proofs, err := computePointProofs(blobs, commitments, versionedHashes, []opening{{blobIndex: 0, z: z}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the blob proof is the proof of the evaluation at the challenge
+	if [48]byte(proofs[0].Proof) != blobProofs[0] {
+		t.Fatalf("expected blob proof %x, got %x", blobProofs[0], proofs[0].Proof)
+	}
+}
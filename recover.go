@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/urfave/cli"
+)
+
+// partialBlob holds some of the cells of a blob along with their proofs
+type partialBlob struct {
+	commitment kzg4844.Commitment
+	indices    []uint64
+	cells      []Cell
+	proofs     []kzg4844.Proof
+}
+
+// verify checks the proofs of the cells
+func (b *partialBlob) verify() error {
+	commitments := make([]kzg4844.Commitment, len(b.cells))
+	for i := range commitments {
+		commitments[i] = b.commitment
+	}
+	return VerifyCellKZGProofBatch(commitments, b.indices, b.cells, b.proofs)
+}
+
+func RecoverApp(cliCtx *cli.Context) error {
+	codec, err := lookupBlobCodec(cliCtx.String(RecoverCodecFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	var sources []string
+	partials := make(map[string][]*partialBlob)
+	for _, name := range cliCtx.StringSlice(RecoverCellsFileFlag.Name) {
+		blobs, err := readPartialBlobs(name)
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, name)
+		}
+		sources = append(sources, name)
+		partials[name] = blobs
+	}
+	if cliCtx.IsSet(RecoverBeaconURLFlag.Name) {
+		var indices []uint64
+		if cliCtx.IsSet(RecoverColumnIndicesFlag.Name) {
+			if indices, err = parseCellIndices(cliCtx.String(RecoverColumnIndicesFlag.Name)); err != nil {
+				return err
+			}
+		}
+		client := newBeaconClient(cliCtx.String(RecoverBeaconURLFlag.Name))
+		sidecars, err := client.DataColumnSidecars(context.Background(), cliCtx.String(RecoverBlockIDFlag.Name), indices...)
+		if err != nil {
+			return fmt.Errorf("%w: unable to download data column sidecars", err)
+		}
+		blobs, err := sidecarPartialBlobs(sidecars)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, client.url)
+		partials[client.url] = blobs
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("either --%s or --%s is required", RecoverCellsFileFlag.Name, RecoverBeaconURLFlag.Name)
+	}
+
+	merged, err := mergePartialBlobs(sources, partials)
+	if err != nil {
+		return err
+	}
+	var payload []byte
+	for i, b := range merged {
+		blob, err := b.recover()
+		if err != nil {
+			return err
+		}
+		versionedHash := kZGToVersionedHash(b.commitment)
+		data, err := codec.Decode(blob[:])
+		if err != nil {
+			return fmt.Errorf("%w: unable to decode blob %v", err, versionedHash)
+		}
+		log.Printf("blob recovered. index=%d versionedHash=%v cells=%d payloadLength=%d", i, versionedHash, len(b.cells), len(data))
+		payload = append(payload, data...)
+	}
+
+	if out := cliCtx.String(RecoverOutputFlag.Name); out != "" {
+		if err := os.WriteFile(out, payload, 0644); err != nil {
+			return fmt.Errorf("%w: unable to write payload", err)
+		}
+		return nil
+	}
+	_, err = os.Stdout.Write(payload)
+	return err
+}
+
+// mergePartialBlobs verifies the cells of every source and merges them, keeping blobs in the order they first appear in
+func mergePartialBlobs(sources []string, partials map[string][]*partialBlob) ([]*partialBlob, error) {
+	var merged []*partialBlob
+	byCommitment := make(map[kzg4844.Commitment]*partialBlob)
+	for _, source := range sources {
+		for _, b := range partials[source] {
+			if err := b.verify(); err != nil {
+				return nil, fmt.Errorf("%w: blob %v from %s", err, kZGToVersionedHash(b.commitment), source)
+			}
+			m, ok := byCommitment[b.commitment]
+			if !ok {
+				m = &partialBlob{commitment: b.commitment}
+				byCommitment[b.commitment] = m
+				merged = append(merged, m)
+			}
+			var added int
+			for i, index := range b.indices {
+				if containsIndex(m.indices, index) {
+					continue
+				}
+				m.indices = append(m.indices, index)
+				m.cells = append(m.cells, b.cells[i])
+				m.proofs = append(m.proofs, b.proofs[i])
+				added++
+			}
+			log.Printf("cells verified. source=%s versionedHash=%v cells=%d new=%d", source, kZGToVersionedHash(b.commitment),
+				len(b.cells), added)
+		}
+	}
+	return merged, nil
+}
+
+// recover reconstructs the blob from at least half of its cells and checks it against the commitment
+func (b *partialBlob) recover() (kzg4844.Blob, error) {
+	versionedHash := kZGToVersionedHash(b.commitment)
+	coefficients, err := recoverPolynomial(b.indices, b.cells)
+	if err != nil {
+		return kzg4844.Blob{}, fmt.Errorf("%w: unable to recover blob %v", err, versionedHash)
+	}
+	blob := coefficientsToBlob(coefficients)
+	commitment, err := kzg4844.BlobToCommitment(blob)
+	if err != nil {
+		return kzg4844.Blob{}, fmt.Errorf("%w: unable to compute commitment", err)
+	}
+	if commitment != b.commitment {
+		return kzg4844.Blob{}, fmt.Errorf("recovered blob %v does not match its commitment", versionedHash)
+	}
+	return blob, nil
+}
+
+func containsIndex(indices []uint64, index uint64) bool {
+	for _, i := range indices {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
+
+// readPartialBlobs reads cells from the JSON output of the cells command, or from data column sidecars as JSON or SSZ,
+// either alone or in a list or beacon node API response
+func readPartialBlobs(name string) ([]*partialBlob, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cells file: %v", err)
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '[' && trimmed[0] != '{') {
+		sidecars, err := unmarshalDataColumnSidecars(data)
+		if err != nil {
+			return nil, err
+		}
+		return sidecarPartialBlobs(sidecars)
+	}
+
+	var list []json.RawMessage
+	if trimmed[0] == '{' {
+		var resp struct {
+			Data []json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(trimmed, &resp); err != nil {
+			return nil, fmt.Errorf("%w: invalid cells file", err)
+		}
+		if list = resp.Data; list == nil {
+			// a single sidecar
+			list = []json.RawMessage{trimmed}
+		}
+	} else if err := json.Unmarshal(trimmed, &list); err != nil {
+		return nil, fmt.Errorf("%w: invalid cells file", err)
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(list[0], &fields); err != nil {
+		return nil, fmt.Errorf("%w: invalid cells file", err)
+	}
+	if _, ok := fields["column"]; ok {
+		sidecars := make([]*DataColumnSidecar, len(list))
+		for i := range list {
+			sidecars[i] = new(DataColumnSidecar)
+			if err := json.Unmarshal(list[i], sidecars[i]); err != nil {
+				return nil, fmt.Errorf("%w: invalid data column sidecar %d", err, i)
+			}
+		}
+		return sidecarPartialBlobs(sidecars)
+	}
+
+	var blobs []*partialBlob
+	for i := range list {
+		var cells blobCells
+		if err := json.Unmarshal(list[i], &cells); err != nil {
+			return nil, fmt.Errorf("%w: invalid cells of blob %d", err, i)
+		}
+		b := new(partialBlob)
+		if b.commitment, b.indices, b.cells, b.proofs, err = cells.unpack(); err != nil {
+			return nil, fmt.Errorf("%w: blob %d", err, cells.BlobIndex)
+		}
+		blobs = append(blobs, b)
+	}
+	return blobs, nil
+}
+
+// sidecarPartialBlobs checks that the data column sidecars belong to the same block and transposes their columns into
+// the cells of each blob
+func sidecarPartialBlobs(sidecars []*DataColumnSidecar) ([]*partialBlob, error) {
+	var blobs []*partialBlob
+	for i, sidecar := range sidecars {
+		if sidecar.Index >= cellsPerExtBlob {
+			return nil, fmt.Errorf("invalid column index %d", sidecar.Index)
+		}
+		if err := sidecar.VerifyInclusionProof(); err != nil {
+			return nil, fmt.Errorf("%w: column %d", err, sidecar.Index)
+		}
+		if i == 0 {
+			for _, commitment := range sidecar.KZGCommitments {
+				blobs = append(blobs, &partialBlob{commitment: commitment})
+			}
+		} else if root, first := sidecar.SignedBlockHeader.Message.HashTreeRoot(), sidecars[0].SignedBlockHeader.Message.HashTreeRoot(); root != first {
+			return nil, fmt.Errorf("data column sidecars belong to different blocks: %v and %v", first, root)
+		}
+		for j := range sidecar.Column {
+			blobs[j].indices = append(blobs[j].indices, sidecar.Index)
+			blobs[j].cells = append(blobs[j].cells, sidecar.Column[j])
+			blobs[j].proofs = append(blobs[j].proofs, sidecar.KZGProofs[j])
+		}
+		log.Printf("data column sidecar verified. slot=%d column=%d blobs=%d", sidecar.Slot(), sidecar.Index, len(sidecar.Column))
+	}
+	return blobs, nil
+}
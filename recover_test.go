@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// testDataColumnSidecars builds the sidecars of the given columns of a block with a single blob, with valid inclusion
+// proofs
+func testDataColumnSidecars(commitment kzg4844.Commitment, cells []Cell, proofs []kzg4844.Proof, columns []uint64) []*DataColumnSidecar {
+	leaves := make([][32]byte, maxBlobCommitmentsPerBlock)
+	leaves[0] = commitmentRoot(commitment[:])
+	var length [32]byte
+	binary.LittleEndian.PutUint64(length[:], 1)
+	bodyLeaves := make([][32]byte, 16)
+	for j := range bodyLeaves {
+		bodyLeaves[j][0] = byte(j + 1)
+	}
+	bodyLeaves[blobKzgCommitmentsIndex] = hashPair(merkleize(leaves), length)
+	bodyRoot, branch := merkleBranch(bodyLeaves, blobKzgCommitmentsIndex)
+
+	var sidecars []*DataColumnSidecar
+	for _, column := range columns {
+		sidecar := &DataColumnSidecar{
+			Index:                        column,
+			Column:                       []Cell{cells[column]},
+			KZGCommitments:               []kzg4844.Commitment{commitment},
+			KZGProofs:                    []kzg4844.Proof{proofs[column]},
+			KZGCommitmentsInclusionProof: branch,
+		}
+		sidecar.SignedBlockHeader.Message.Slot = 100
+		sidecar.SignedBlockHeader.Message.BodyRoot = bodyRoot
+		sidecar.SignedBlockHeader.Signature = make([]byte, 96)
+		sidecars = append(sidecars, sidecar)
+	}
+	return sidecars
+}
+
+func marshalDataColumnSidecarSSZ(s *DataColumnSidecar) []byte {
+	b := binary.LittleEndian.AppendUint64(nil, s.Index)
+	offset := uint32(dataColumnSidecarFixedSize)
+	b = binary.LittleEndian.AppendUint32(b, offset)
+	offset += uint32(len(s.Column) * bytesPerCell)
+	b = binary.LittleEndian.AppendUint32(b, offset)
+	offset += uint32(len(s.KZGCommitments) * 48)
+	b = binary.LittleEndian.AppendUint32(b, offset)
+	h := s.SignedBlockHeader.Message
+	b = binary.LittleEndian.AppendUint64(b, h.Slot)
+	b = binary.LittleEndian.AppendUint64(b, h.ProposerIndex)
+	b = append(append(append(b, h.ParentRoot[:]...), h.StateRoot[:]...), h.BodyRoot[:]...)
+	b = append(b, s.SignedBlockHeader.Signature...)
+	for _, p := range s.KZGCommitmentsInclusionProof {
+		b = append(b, p[:]...)
+	}
+	for i := range s.Column {
+		b = append(b, s.Column[i][:]...)
+	}
+	for i := range s.KZGCommitments {
+		b = append(b, s.KZGCommitments[i][:]...)
+	}
+	for i := range s.KZGProofs {
+		b = append(b, s.KZGProofs[i][:]...)
+	}
+	return b
+}
+
+func TestRecoverPartialBlobs(t *testing.T) {
+	blob, commitment, cells, proofs := computeTestCells(t)
+	dir := t.TempDir()
+
+	// columns 0-39 as an SSZ list of sidecars
+	var columns []uint64
+	for i := uint64(0); i < 50; i++ {
+		columns = append(columns, i)
+	}
+	sidecars := testDataColumnSidecars(commitment, cells, proofs, columns[:40])
+	var ssz, offsets []byte
+	for _, sidecar := range sidecars {
+		offsets = binary.LittleEndian.AppendUint32(offsets, uint32(4*len(sidecars)+len(ssz)))
+		ssz = append(ssz, marshalDataColumnSidecarSSZ(sidecar)...)
+	}
+	sszFile := filepath.Join(dir, "columns.ssz")
+	if err := os.WriteFile(sszFile, append(offsets, ssz...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// columns 30-49 as a beacon node API response
+	sidecars = testDataColumnSidecars(commitment, cells, proofs, columns[30:])
+	var resp struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	for _, sidecar := range sidecars {
+		resp.Data = append(resp.Data, map[string]interface{}{
+			"index":                           strconv.FormatUint(sidecar.Index, 10),
+			"column":                          []hexutil.Bytes{sidecar.Column[0][:]},
+			"kzg_commitments":                 []hexutil.Bytes{sidecar.KZGCommitments[0][:]},
+			"kzg_proofs":                      []hexutil.Bytes{sidecar.KZGProofs[0][:]},
+			"signed_block_header":             sidecar.SignedBlockHeader,
+			"kzg_commitments_inclusion_proof": sidecar.KZGCommitmentsInclusionProof,
+		})
+	}
+	jsonFile := filepath.Join(dir, "columns.json")
+	writeJSON(t, jsonFile, resp)
+
+	// cells 100-113 as printed by the cells command
+	cellsFile := filepath.Join(dir, "cells.json")
+	writeJSON(t, cellsFile, []blobCells{newBlobCells(0, commitment, cells, proofs, []uint64{100, 101, 102, 103, 104, 105, 106,
+		107, 108, 109, 110, 111, 112, 113})})
+
+	sources := []string{sszFile, jsonFile, cellsFile}
+	partials := make(map[string][]*partialBlob)
+	for _, name := range sources {
+		blobs, err := readPartialBlobs(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(blobs) != 1 || blobs[0].commitment != commitment {
+			t.Fatalf("%s: unexpected blobs", name)
+		}
+		partials[name] = blobs
+	}
+	if n := len(partials[jsonFile][0].cells); n != 20 {
+		t.Fatalf("expected 20 cells, got %d", n)
+	}
+
+	merged, err := mergePartialBlobs(sources, partials)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 1 || len(merged[0].cells) != 64 {
+		t.Fatalf("unexpected merged blobs")
+	}
+	recovered, err := merged[0].recover()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovered != blob {
+		t.Fatal("blob was not recovered")
+	}
+
+	// less than half of the cells
+	merged, err = mergePartialBlobs(sources[:2], partials)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := merged[0].recover(); err == nil {
+		t.Fatal("expected recovery from less than half of the cells to fail")
+	}
+
+	// a tampered cell
+	partials[cellsFile][0].cells[0][0] ^= 1
+	if _, err := mergePartialBlobs(sources, partials); err == nil {
+		t.Fatal("expected tampered cell to fail verification")
+	}
+}
+
+func writeJSON(t *testing.T, name string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDataColumnSidecarInclusionProof(t *testing.T) {
+	sidecars := testDataColumnSidecars(kzg4844.Commitment{1}, make([]Cell, 1), make([]kzg4844.Proof, 1), []uint64{0})
+	if err := sidecars[0].VerifyInclusionProof(); err != nil {
+		t.Fatal(err)
+	}
+	sidecars[0].KZGCommitments[0][0] = 2
+	if err := sidecars[0].VerifyInclusionProof(); err == nil {
+		t.Fatal("expected invalid inclusion proof")
+	}
+
+	decoded, err := unmarshalDataColumnSidecars(marshalDataColumnSidecarSSZ(sidecars[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || decoded[0].KZGCommitments[0] != sidecars[0].KZGCommitments[0] ||
+		decoded[0].SignedBlockHeader.Message.HashTreeRoot() != sidecars[0].SignedBlockHeader.Message.HashTreeRoot() {
+		t.Fatal("unexpected decoded sidecar")
+	}
+	if decoded[0].KZGCommitmentsInclusionProof[3] != sidecars[0].KZGCommitmentsInclusionProof[3] {
+		t.Fatal("unexpected inclusion proof")
+	}
+}
+
+func TestUnmarshalDataColumnSidecarsMalformed(t *testing.T) {
+	list := func(size int, offsets ...uint32) []byte {
+		b := make([]byte, size)
+		for i, offset := range offsets {
+			binary.LittleEndian.PutUint32(b[i*4:], offset)
+		}
+		return b
+	}
+	valid := marshalDataColumnSidecarSSZ(testDataColumnSidecars(kzg4844.Commitment{1}, make([]Cell, 1), make([]kzg4844.Proof, 1), []uint64{0})[0])
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{name: "short", input: []byte{4, 0, 0}},
+		{name: "zero offset", input: list(500, 0)},
+		{name: "unaligned offset", input: list(500, 6)},
+		{name: "first offset out of bounds", input: list(500, 1000)},
+		{name: "later offset out of bounds", input: list(500, 8, 1000)},
+		{name: "decreasing offsets", input: list(500, 12, 300, 200)},
+		{name: "truncated sidecar", input: append(list(8, 8, 8+uint32(len(valid))), valid[:len(valid)-1]...)},
+		{name: "truncated single sidecar", input: valid[:len(valid)-1]},
+	}
+	for _, tt := range tests {
+		if _, err := unmarshalDataColumnSidecars(tt.input); err == nil {
+			t.Fatalf("%s: expected error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// scrollMinCodecVersion is the first codec version with the blob envelope, introduced with EuclidV2
+	scrollMinCodecVersion = 7
+	scrollMaxCodecVersion = 8
+
+	scrollBlockContextSize = 52
+)
+
+// scrollZstdMagic is prepended to the payload, as Scroll compresses batches without the zstd frame magic number
+var scrollZstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+type scrollBlock struct {
+	Timestamp       uint64       `json:"timestamp"`
+	BaseFee         *hexutil.Big `json:"base_fee"`
+	GasLimit        uint64       `json:"gas_limit"`
+	NumTransactions uint16       `json:"num_transactions"`
+	NumL1Messages   uint16       `json:"num_l1_messages"`
+}
+
+// scrollBatch is the payload of a Scroll batch blob
+type scrollBatch struct {
+	Version                uint8           `json:"version"`
+	Compressed             bool            `json:"compressed"`
+	PrevL1MessageQueueHash common.Hash     `json:"prev_l1_message_queue_hash"`
+	PostL1MessageQueueHash common.Hash     `json:"post_l1_message_queue_hash"`
+	InitialL2BlockNumber   uint64          `json:"initial_l2_block_number"`
+	Blocks                 []scrollBlock   `json:"blocks"`
+	L2Transactions         []hexutil.Bytes `json:"l2_transactions"`
+}
+
+// scrollDecoder prints the blocks and L2 transactions of Scroll batches, which are encoded into a single blob
+type scrollDecoder struct {
+	out *json.Encoder
+}
+
+func newScrollDecoder(out io.Writer) *scrollDecoder {
+	return &scrollDecoder{out: json.NewEncoder(out)}
+}
+
+func (d *scrollDecoder) Codec() BlobCodec {
+	return paddedCodec{}
+}
+
+func (d *scrollDecoder) AddBlob(data []byte) error {
+	batch, err := decodeScrollBatch(data)
+	if err != nil {
+		return err
+	}
+	return d.out.Encode(batch)
+}
+
+func (d *scrollDecoder) Flush() error {
+	return nil
+}
+
+// decodeScrollBatch decodes the blob envelope (version, 3 byte payload size and compression flag) and the payload of
+// a batch
+func decodeScrollBatch(data []byte) (*scrollBatch, error) {
+	r := &byteReader{data: data}
+	batch := &scrollBatch{Version: r.uint8()}
+	if batch.Version < scrollMinCodecVersion || batch.Version > scrollMaxCodecVersion {
+		return nil, fmt.Errorf("unsupported scroll codec version %d", batch.Version)
+	}
+	size := r.uint24()
+	switch r.uint8() {
+	case 0:
+	case 1:
+		batch.Compressed = true
+	default:
+		return nil, fmt.Errorf("invalid compression flag")
+	}
+	payload := r.bytes(int(size))
+	if r.err != nil {
+		return nil, fmt.Errorf("%w: invalid payload size %d", r.err, size)
+	}
+	if batch.Compressed {
+		zr, err := zstd.NewReader(bytes.NewReader(append(append([]byte{}, scrollZstdMagic...), payload...)))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		if payload, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("%w: unable to decompress payload", err)
+		}
+	}
+
+	r = &byteReader{data: payload}
+	batch.PrevL1MessageQueueHash = common.BytesToHash(r.bytes(32))
+	batch.PostL1MessageQueueHash = common.BytesToHash(r.bytes(32))
+	batch.InitialL2BlockNumber = r.uint64()
+	numBlocks := int(r.uint16())
+	if r.err != nil {
+		return nil, fmt.Errorf("%w: invalid payload header", r.err)
+	}
+	var numL2Txs int
+	for i := 0; i < numBlocks; i++ {
+		b := r.bytes(scrollBlockContextSize)
+		if r.err != nil {
+			return nil, fmt.Errorf("%w: invalid context of block %d", r.err, i)
+		}
+		block := scrollBlock{
+			Timestamp:       binary.BigEndian.Uint64(b[0:8]),
+			BaseFee:         (*hexutil.Big)(new(big.Int).SetBytes(b[8:40])),
+			GasLimit:        binary.BigEndian.Uint64(b[40:48]),
+			NumTransactions: binary.BigEndian.Uint16(b[48:50]),
+			NumL1Messages:   binary.BigEndian.Uint16(b[50:52]),
+		}
+		if block.NumL1Messages > block.NumTransactions {
+			return nil, fmt.Errorf("block %d has more l1 messages than transactions", i)
+		}
+		numL2Txs += int(block.NumTransactions - block.NumL1Messages)
+		batch.Blocks = append(batch.Blocks, block)
+	}
+
+	txs := payload[r.offset:]
+	for i := 0; i < numL2Txs; i++ {
+		tx, rest, err := splitTransaction(txs)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid transaction %d", err, i)
+		}
+		batch.L2Transactions = append(batch.L2Transactions, tx)
+		txs = rest
+	}
+	return batch, nil
+}
+
+// splitTransaction splits the first binary encoded transaction from b
+func splitTransaction(b []byte) (tx, rest []byte, err error) {
+	if len(b) == 0 {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	offset := 0
+	if b[0] < 0xc0 {
+		// typed transactions are prefixed with their type
+		if b[0] > 0x7f {
+			return nil, nil, fmt.Errorf("invalid transaction type %d", b[0])
+		}
+		offset = 1
+	}
+	kind, _, rest, err := rlp.Split(b[offset:])
+	if err != nil {
+		return nil, nil, err
+	}
+	if kind != rlp.List {
+		return nil, nil, fmt.Errorf("transaction is not an rlp list")
+	}
+	return b[:len(b)-len(rest)], rest, nil
+}
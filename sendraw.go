@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/urfave/cli"
+)
+
+func SendRawApp(cliCtx *cli.Context) error {
+	raw, err := readRawTx(cliCtx.String(SendRawTxFileFlag.Name))
+	if err != nil {
+		return err
+	}
+	tx, version, err := unmarshalBlobTx(raw)
+	if err != nil {
+		return fmt.Errorf("%w: invalid transaction", err)
+	}
+	if cliCtx.IsSet(SendRawBlobFileFlag.Name) {
+		blobs, err := readSidecarBlobs(cliCtx)
+		if err != nil {
+			return err
+		}
+		version = cliCtx.Uint64(SendRawSidecarVersionFlag.Name)
+		if tx, err = attachBlobTxSidecar(tx, blobs, version); err != nil {
+			return err
+		}
+		if raw, err = marshalBlobTx(tx, version); err != nil {
+			return err
+		}
+	}
+	if err := validateBlobTx(tx, version); err != nil {
+		return err
+	}
+	from, _ := types.Sender(types.NewCancunSigner(tx.ChainId()), tx)
+	log.Printf("transaction validated. txhash=%v from=%v nonce=%d blobs=%d sidecarVersion=%d", tx.Hash(), from, tx.Nonce(),
+		len(tx.BlobHashes()), version)
+
+	ctx := context.Background()
+	client, err := ethclient.DialContext(ctx, cliCtx.String(SendRawRPCURLFlag.Name))
+	if err != nil {
+		log.Fatalf("Failed to connect to the Ethereum client: %v", err)
+	}
+	if err := sendRawTransaction(ctx, client, raw); err != nil {
+		log.Fatalf("failed to send transaction: %v", err)
+	}
+	log.Printf("successfully sent transaction. txhash=%v", tx.Hash())
+
+	waitForReceipt(client, tx.Hash())
+	log.Printf("Transaction included. nonce=%d hash=%v", tx.Nonce(), tx.Hash())
+	return nil
+}
+
+// readRawTx reads a file holding a raw transaction or its hex encoding
+func readRawTx(name string) ([]byte, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading transaction file: %v", err)
+	}
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("0x")) {
+		if data, err = hexutil.Decode(string(trimmed)); err != nil {
+			return nil, fmt.Errorf("%w: invalid transaction file", err)
+		}
+	}
+	return data, nil
+}
+
+// readSidecarBlobs reads the blobs of --blob-file, encoded with --codec unless --raw-blob is set
+func readSidecarBlobs(cliCtx *cli.Context) ([]kzg4844.Blob, error) {
+	data, err := os.ReadFile(cliCtx.String(SendRawBlobFileFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("error reading blob file: %v", err)
+	}
+	if cliCtx.Bool(SendRawRawBlobFlag.Name) {
+		return splitRawBlobs(data)
+	}
+	codec, err := lookupBlobCodec(cliCtx.String(SendRawCodecFlag.Name))
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(data)
+}
+
+// attachBlobTxSidecar returns a blob transaction signed without a sidecar with the sidecar of the given version built
+// from the blobs, which must match the versioned hashes of the transaction
+func attachBlobTxSidecar(tx *types.Transaction, blobs []kzg4844.Blob, version uint64) (*types.Transaction, error) {
+	if tx.Type() != types.BlobTxType {
+		return nil, fmt.Errorf("unexpected transaction type %d", tx.Type())
+	}
+	if tx.BlobTxSidecar() != nil {
+		return nil, fmt.Errorf("blob transaction %v already has a sidecar", tx.Hash())
+	}
+	sidecar, versionedHashes, err := newBlobTxSidecar(blobs, version)
+	if err != nil {
+		return nil, err
+	}
+	hashes := tx.BlobHashes()
+	if len(versionedHashes) != len(hashes) {
+		return nil, fmt.Errorf("expected %d blobs, the blob file holds %d", len(hashes), len(versionedHashes))
+	}
+	for i := range hashes {
+		if versionedHashes[i] != hashes[i] {
+			return nil, fmt.Errorf("versioned hash mismatch of blob %d. expected %v, got %v", i, hashes[i], versionedHashes[i])
+		}
+	}
+
+	body, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	// the version 0 decoding of geth accepts any number of proofs
+	enc, err := rlp.EncodeToBytes(&blobTxWithBlobProofs{
+		Tx:          body[1:],
+		Blobs:       sidecar.Blobs,
+		Commitments: sidecar.Commitments,
+		Proofs:      sidecar.Proofs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	withSidecar := new(types.Transaction)
+	if err := withSidecar.UnmarshalBinary(append([]byte{types.BlobTxType}, enc...)); err != nil {
+		return nil, err
+	}
+	return withSidecar, nil
+}
+
+// validateBlobTx checks the signature of a blob transaction, and that it has a sidecar of the given version whose
+// commitments match the versioned hashes of the transaction and whose proofs are valid
+func validateBlobTx(tx *types.Transaction, version uint64) error {
+	if tx.Type() != types.BlobTxType {
+		return fmt.Errorf("unexpected transaction type %d", tx.Type())
+	}
+	if _, err := types.Sender(types.NewCancunSigner(tx.ChainId()), tx); err != nil {
+		return fmt.Errorf("%w: invalid signature", err)
+	}
+	sidecar := tx.BlobTxSidecar()
+	if sidecar == nil {
+		return fmt.Errorf("blob transaction %v has no sidecar", tx.Hash())
+	}
+
+	hashes := tx.BlobHashes()
+	if len(sidecar.Blobs) != len(hashes) || len(sidecar.Commitments) != len(hashes) {
+		return fmt.Errorf("expected %d blobs and commitments, got %d and %d", len(hashes), len(sidecar.Blobs), len(sidecar.Commitments))
+	}
+	for i, commitment := range sidecar.Commitments {
+		if versionedHash := kZGToVersionedHash(commitment); versionedHash != hashes[i] {
+			return fmt.Errorf("versioned hash mismatch of blob %d. expected %v, got %v", i, hashes[i], versionedHash)
+		}
+	}
+	if err := validateBlobs(sidecar.Blobs); err != nil {
+		return err
+	}
+
+	switch version {
+	case sidecarVersion0:
+		if len(sidecar.Proofs) != len(sidecar.Blobs) {
+			return fmt.Errorf("expected %d blob proofs, got %d", len(sidecar.Blobs), len(sidecar.Proofs))
+		}
+		for i := range sidecar.Blobs {
+			if err := kzg4844.VerifyBlobProof(sidecar.Blobs[i], sidecar.Commitments[i], sidecar.Proofs[i]); err != nil {
+				return fmt.Errorf("%w: invalid proof of blob %d", err, i)
+			}
+		}
+	case sidecarVersion1:
+		if len(sidecar.Proofs) != len(sidecar.Blobs)*cellsPerExtBlob {
+			return fmt.Errorf("expected %d cell proofs, got %d", len(sidecar.Blobs)*cellsPerExtBlob, len(sidecar.Proofs))
+		}
+		var (
+			commitments []kzg4844.Commitment
+			indices     []uint64
+			cells       []Cell
+		)
+		for i := range sidecar.Blobs {
+			coefficients, err := blobCoefficients(sidecar.Blobs[i][:])
+			if err != nil {
+				return err
+			}
+			for j, cell := range polynomialCells(coefficients) {
+				commitments = append(commitments, sidecar.Commitments[i])
+				indices = append(indices, uint64(j))
+				cells = append(cells, cell)
+			}
+		}
+		if err := VerifyCellKZGProofBatch(commitments, indices, cells, sidecar.Proofs); err != nil {
+			if errors.Is(err, errInvalidCellProof) {
+				return fmt.Errorf("%w: cell proofs don't match the blobs", err)
+			}
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported sidecar version %d", version)
+	}
+	return nil
+}
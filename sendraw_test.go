@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/urfave/cli"
+)
+
+func TestValidateBlobTx(t *testing.T) {
+	blobs, commitments, proofs, hashes, err := EncodeBlobs([]byte("hello"), legacyCodec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sidecar := &types.BlobTxSidecar{Blobs: blobs, Commitments: commitments, Proofs: proofs}
+	if err := validateBlobTx(signedBlobTx(t, sidecar, hashes), sidecarVersion0); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateBlobTx(signedBlobTx(t, sidecar, []common.Hash{{1}}), sidecarVersion0); err == nil {
+		t.Fatal("expected versioned hash mismatch")
+	}
+	if err := validateBlobTx(signedBlobTx(t, nil, hashes), sidecarVersion0); err == nil {
+		t.Fatal("expected missing sidecar to be rejected")
+	}
+	badProofs := &types.BlobTxSidecar{Blobs: blobs, Commitments: commitments, Proofs: []kzg4844.Proof{kzg4844.Proof(commitments[0])}}
+	if err := validateBlobTx(signedBlobTx(t, badProofs, hashes), sidecarVersion0); err == nil {
+		t.Fatal("expected invalid blob proof")
+	}
+
+	blob, commitment, _, cellProofs := computeTestCells(t)
+	cellSidecar := &types.BlobTxSidecar{
+		Blobs:       []kzg4844.Blob{blob},
+		Commitments: []kzg4844.Commitment{commitment},
+		Proofs:      append([]kzg4844.Proof{}, cellProofs...),
+	}
+	cellHashes := []common.Hash{kZGToVersionedHash(commitment)}
+	if err := validateBlobTx(signedBlobTx(t, cellSidecar, cellHashes), sidecarVersion1); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateBlobTx(signedBlobTx(t, cellSidecar, cellHashes), sidecarVersion0); err == nil {
+		t.Fatal("expected cell proofs to be rejected by version 0")
+	}
+	cellSidecar.Proofs[0], cellSidecar.Proofs[1] = cellSidecar.Proofs[1], cellSidecar.Proofs[0]
+	if err := validateBlobTx(signedBlobTx(t, cellSidecar, cellHashes), sidecarVersion1); err == nil {
+		t.Fatal("expected invalid cell proofs")
+	}
+}
+
+func TestSendRawAppWithoutSidecar(t *testing.T) {
+	raw, err := signedBlobTx(t, nil, []common.Hash{{1}}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txFile := filepath.Join(t.TempDir(), "tx.rlp")
+	if err := os.WriteFile(txFile, raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+	set := flag.NewFlagSet("send-raw", flag.ContinueOnError)
+	for _, f := range SendRawFlags {
+		f.Apply(set)
+	}
+	// the node is unreachable, so only an error returned before dialing can pass
+	if err := set.Parse([]string{"--tx-file", txFile, "--rpc-url", "http://127.0.0.1:1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SendRawApp(cli.NewContext(cli.NewApp(), set, nil)); err == nil {
+		t.Fatal("expected transaction without sidecar to be rejected")
+	}
+}
+
+func TestAttachBlobTxSidecar(t *testing.T) {
+	blobs, err := legacyCodec{}.Encode([]byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, hashes, err := BlobCommitments(blobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := signedBlobTx(t, nil, hashes)
+	for _, version := range []uint64{sidecarVersion0, sidecarVersion1} {
+		withSidecar, err := attachBlobTxSidecar(tx, blobs, version)
+		if err != nil {
+			t.Fatalf("version %d: %v", version, err)
+		}
+		if withSidecar.Hash() != tx.Hash() {
+			t.Fatalf("version %d: unexpected transaction hash %v", version, withSidecar.Hash())
+		}
+		if err := validateBlobTx(withSidecar, version); err != nil {
+			t.Fatalf("version %d: %v", version, err)
+		}
+		if _, err := attachBlobTxSidecar(withSidecar, blobs, version); err == nil {
+			t.Fatalf("version %d: expected transaction with a sidecar to be rejected", version)
+		}
+	}
+
+	other, err := legacyCodec{}.Encode([]byte("other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := attachBlobTxSidecar(tx, other, sidecarVersion0); err == nil {
+		t.Fatal("expected versioned hash mismatch")
+	}
+	if _, err := attachBlobTxSidecar(tx, append(blobs, other...), sidecarVersion0); err == nil {
+		t.Fatal("expected blob count mismatch")
+	}
+}
+
+// newTestSendRawServer accepts raw transactions with eth_sendRawTransaction and reports them as included
+func newTestSendRawServer(t *testing.T, sent *[]byte) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []string        `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Params) != 1 {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		var result interface{}
+		switch req.Method {
+		case "eth_sendRawTransaction":
+			*sent = hexutil.MustDecode(req.Params[0])
+		case "eth_getTransactionReceipt":
+			result = &types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{}, TxHash: common.HexToHash(req.Params[0])}
+		default:
+			http.Error(w, fmt.Sprintf("unexpected request %s", req.Method), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSendRawAppWithBlobFile(t *testing.T) {
+	dir := t.TempDir()
+	blobFile := filepath.Join(dir, "blob.txt")
+	if err := os.WriteFile(blobFile, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	blobs, err := legacyCodec{}.Encode([]byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, hashes, err := BlobCommitments(blobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := signedBlobTx(t, nil, hashes)
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txFile := filepath.Join(dir, "tx.rlp")
+	if err := os.WriteFile(txFile, []byte(hexutil.Encode(raw)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var sent []byte
+	srv := newTestSendRawServer(t, &sent)
+
+	set := flag.NewFlagSet("send-raw", flag.ContinueOnError)
+	for _, f := range SendRawFlags {
+		f.Apply(set)
+	}
+	if err := set.Parse([]string{"--tx-file", txFile, "--rpc-url", srv.URL, "--blob-file", blobFile, "--sidecar-version", "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SendRawApp(cli.NewContext(cli.NewApp(), set, nil)); err != nil {
+		t.Fatal(err)
+	}
+	sentTx, version, err := unmarshalBlobTx(sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sentTx.Hash() != tx.Hash() || version != sidecarVersion1 {
+		t.Fatalf("unexpected transaction %v with sidecar version %d", sentTx.Hash(), version)
+	}
+	if err := validateBlobTx(sentTx, version); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// sidecarVersion0 is the EIP-4844 network encoding, with a blob proof per blob
+	sidecarVersion0 = 0
+	// sidecarVersion1 is the EIP-7594 network encoding, with the proofs of all cells of each blob
+	sidecarVersion1 = 1
+)
+
+// blobTxWithCellProofs is the network encoding of a blob transaction with a version 1 sidecar:
+// rlp([tx_payload_body, wrapper_version, blobs, commitments, cell_proofs])
+type blobTxWithCellProofs struct {
+	Tx          rlp.RawValue
+	Version     uint8
+	Blobs       []kzg4844.Blob
+	Commitments []kzg4844.Commitment
+	Proofs      []kzg4844.Proof
+}
+
+// blobTxWithBlobProofs is the network encoding of a blob transaction with a version 0 sidecar:
+// rlp([tx_payload_body, blobs, commitments, proofs])
+type blobTxWithBlobProofs struct {
+	Tx          rlp.RawValue
+	Blobs       []kzg4844.Blob
+	Commitments []kzg4844.Commitment
+	Proofs      []kzg4844.Proof
+}
+
+// newBlobTxSidecar commits to the blobs and builds the sidecar of the given version along with the versioned hashes.
+// Version 1 sidecars hold CELLS_PER_EXT_BLOB cell proofs per blob in place of the blob proofs.
+func newBlobTxSidecar(blobs []kzg4844.Blob, version uint64) (*types.BlobTxSidecar, []common.Hash, error) {
+	switch version {
+	case sidecarVersion0:
+		commitments, proofs, versionedHashes, err := CommitBlobs(blobs)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &types.BlobTxSidecar{Blobs: blobs, Commitments: commitments, Proofs: proofs}, versionedHashes, nil
+	case sidecarVersion1:
+		commitments, _, cellProofs, err := ComputeBlobCells(blobs)
+		if err != nil {
+			return nil, nil, err
+		}
+		sidecar := &types.BlobTxSidecar{Blobs: blobs, Commitments: commitments}
+		var versionedHashes []common.Hash
+		for i := range blobs {
+			sidecar.Proofs = append(sidecar.Proofs, cellProofs[i]...)
+			versionedHashes = append(versionedHashes, kZGToVersionedHash(commitments[i]))
+		}
+		return sidecar, versionedHashes, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported sidecar version %d", version)
+	}
+}
+
+// marshalBlobTx returns the network encoding of a blob transaction with its sidecar of the given version
+func marshalBlobTx(tx *types.Transaction, version uint64) ([]byte, error) {
+	sidecar := tx.BlobTxSidecar()
+	if sidecar == nil {
+		return nil, errors.New("blob transaction has no sidecar")
+	}
+	switch version {
+	case sidecarVersion0:
+		if len(sidecar.Proofs) != len(sidecar.Blobs) {
+			return nil, fmt.Errorf("expected %d blob proofs, got %d", len(sidecar.Blobs), len(sidecar.Proofs))
+		}
+		return tx.MarshalBinary()
+	case sidecarVersion1:
+		if len(sidecar.Proofs) != len(sidecar.Blobs)*cellsPerExtBlob {
+			return nil, fmt.Errorf("expected %d cell proofs, got %d", len(sidecar.Blobs)*cellsPerExtBlob, len(sidecar.Proofs))
+		}
+		body, err := tx.WithoutBlobTxSidecar().MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		enc, err := rlp.EncodeToBytes(&blobTxWithCellProofs{
+			Tx:          body[1:],
+			Version:     sidecarVersion1,
+			Blobs:       sidecar.Blobs,
+			Commitments: sidecar.Commitments,
+			Proofs:      sidecar.Proofs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{types.BlobTxType}, enc...), nil
+	default:
+		return nil, fmt.Errorf("unsupported sidecar version %d", version)
+	}
+}
+
+// unmarshalBlobTx decodes a transaction in its canonical or network encoding, and returns the version of its sidecar
+// if it has one
+func unmarshalBlobTx(b []byte) (*types.Transaction, uint64, error) {
+	tx := new(types.Transaction)
+	if len(b) == 0 || b[0] != types.BlobTxType {
+		return tx, sidecarVersion0, tx.UnmarshalBinary(b)
+	}
+	elems, _, err := rlp.SplitList(b[1:])
+	if err != nil {
+		return nil, 0, err
+	}
+	kind, _, rest, err := rlp.Split(elems)
+	if err != nil {
+		return nil, 0, err
+	}
+	if kind != rlp.List {
+		// the canonical encoding, without sidecar
+		return tx, sidecarVersion0, tx.UnmarshalBinary(b)
+	}
+	if kind, _, _, err = rlp.Split(rest); err != nil {
+		return nil, 0, err
+	}
+	if kind == rlp.List {
+		return tx, sidecarVersion0, tx.UnmarshalBinary(b)
+	}
+
+	var inner blobTxWithCellProofs
+	if err := rlp.DecodeBytes(b[1:], &inner); err != nil {
+		return nil, 0, err
+	}
+	if inner.Version != sidecarVersion1 {
+		return nil, 0, fmt.Errorf("unsupported sidecar version %d", inner.Version)
+	}
+	// the version 0 decoding of geth accepts any number of proofs
+	enc, err := rlp.EncodeToBytes(&blobTxWithBlobProofs{
+		Tx:          inner.Tx,
+		Blobs:       inner.Blobs,
+		Commitments: inner.Commitments,
+		Proofs:      inner.Proofs,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return tx, sidecarVersion1, tx.UnmarshalBinary(append([]byte{types.BlobTxType}, enc...))
+}
+
+// sendBlobTx broadcasts a signed blob transaction with its sidecar of the given version
+func sendBlobTx(ctx context.Context, client *ethclient.Client, tx *types.Transaction, version uint64) error {
+	data, err := marshalBlobTx(tx, version)
+	if err != nil {
+		return err
+	}
+	return sendRawTransaction(ctx, client, data)
+}
+
+func sendRawTransaction(ctx context.Context, client *ethclient.Client, data []byte) error {
+	return client.Client().CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Encode(data))
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
+	"github.com/urfave/cli"
+)
+
+func signedBlobTx(t *testing.T, sidecar *types.BlobTxSidecar, hashes []common.Hash) *types.Transaction {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := types.NewTx(&types.BlobTx{
+		ChainID:    uint256.NewInt(1),
+		Nonce:      1,
+		GasTipCap:  uint256.NewInt(1),
+		GasFeeCap:  uint256.NewInt(10),
+		Gas:        21000,
+		To:         common.Address{1},
+		Value:      uint256.NewInt(0),
+		BlobFeeCap: uint256.NewInt(1),
+		BlobHashes: hashes,
+		Sidecar:    sidecar,
+	})
+	signedTx, err := types.SignTx(tx, types.NewCancunSigner(big.NewInt(1)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signedTx
+}
+
+func TestMarshalBlobTx(t *testing.T) {
+	sidecar := &types.BlobTxSidecar{
+		Blobs:       make([]kzg4844.Blob, 2),
+		Commitments: []kzg4844.Commitment{{1}, {2}},
+		Proofs:      make([]kzg4844.Proof, 2*cellsPerExtBlob),
+	}
+	for i := range sidecar.Proofs {
+		sidecar.Proofs[i][0] = byte(i)
+	}
+	tx := signedBlobTx(t, sidecar, []common.Hash{{1}, {2}})
+
+	if _, err := marshalBlobTx(tx, sidecarVersion0); err == nil {
+		t.Fatal("expected cell proofs to be rejected by version 0")
+	}
+	enc, err := marshalBlobTx(tx, sidecarVersion1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc[0] != types.BlobTxType {
+		t.Fatalf("unexpected type %d", enc[0])
+	}
+	var inner blobTxWithCellProofs
+	if err := rlp.DecodeBytes(enc[1:], &inner); err != nil {
+		t.Fatal(err)
+	}
+	if inner.Version != sidecarVersion1 || len(inner.Proofs) != 2*cellsPerExtBlob {
+		t.Fatalf("unexpected wrapper version %d with %d proofs", inner.Version, len(inner.Proofs))
+	}
+	canonical, err := tx.WithoutBlobTxSidecar().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(inner.Tx, canonical[1:]) {
+		t.Fatal("unexpected transaction payload")
+	}
+
+	decoded, version, err := unmarshalBlobTx(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != sidecarVersion1 || decoded.Hash() != tx.Hash() {
+		t.Fatalf("unexpected version %d and hash %v", version, decoded.Hash())
+	}
+	if got := decoded.BlobTxSidecar(); got == nil || len(got.Proofs) != len(sidecar.Proofs) || got.Proofs[5] != sidecar.Proofs[5] {
+		t.Fatal("unexpected sidecar")
+	}
+
+	// without sidecar
+	decoded, _, err = unmarshalBlobTx(canonical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Hash() != tx.Hash() || decoded.BlobTxSidecar() != nil {
+		t.Fatal("unexpected decoding of the canonical encoding")
+	}
+
+	// version 0 round trip
+	tx = signedBlobTx(t, &types.BlobTxSidecar{
+		Blobs:       sidecar.Blobs,
+		Commitments: sidecar.Commitments,
+		Proofs:      sidecar.Proofs[:2],
+	}, []common.Hash{{1}, {2}})
+	enc, err = marshalBlobTx(tx, sidecarVersion0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, version, err = unmarshalBlobTx(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != sidecarVersion0 || decoded.Hash() != tx.Hash() || len(decoded.BlobTxSidecar().Proofs) != 2 {
+		t.Fatalf("unexpected version %d and hash %v", version, decoded.Hash())
+	}
+}
+
+func TestTxAppInvalidSidecarVersion(t *testing.T) {
+	blobFile := filepath.Join(t.TempDir(), "blob.txt")
+	if err := os.WriteFile(blobFile, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	set := flag.NewFlagSet("tx", flag.ContinueOnError)
+	for _, f := range TxFlags {
+		f.Apply(set)
+	}
+	if err := set.Parse([]string{"--blob-file", blobFile, "--sidecar-version", "2"}); err != nil {
+		t.Fatal(err)
+	}
+	err := TxApp(cli.NewContext(cli.NewApp(), set, nil))
+	if err == nil || err.Error() != "unsupported sidecar version 2" {
+		t.Fatalf("expected unsupported sidecar version, got %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+const (
+	// kzgCommitmentsInclusionProofDepth is the depth of the merkle branch proving the blob_kzg_commitments list is part
+	// of a block body
+	kzgCommitmentsInclusionProofDepth = 4
+	// kzgCommitmentInclusionProofDepth is the depth of the merkle branch proving a commitment is part of a block body
+	kzgCommitmentInclusionProofDepth = 17
+	// blobKzgCommitmentsIndex is the index of the blob_kzg_commitments field in the BeaconBlockBody container
+	blobKzgCommitmentsIndex = 11
+	// maxBlobCommitmentsPerBlock is the limit of the blob_kzg_commitments list
+	maxBlobCommitmentsPerBlock = 4096
+	// blobCommitmentsListDepth is the depth of the blob_kzg_commitments list, including the length mix-in
+	blobCommitmentsListDepth = 13
+)
+
+// HashTreeRoot computes the SSZ hash tree root of the header, i.e. the block root
+func (h *BeaconBlockHeader) HashTreeRoot() common.Hash {
+	chunks := make([][32]byte, 8)
+	binary.LittleEndian.PutUint64(chunks[0][:], h.Slot)
+	binary.LittleEndian.PutUint64(chunks[1][:], h.ProposerIndex)
+	chunks[2] = h.ParentRoot
+	chunks[3] = h.StateRoot
+	chunks[4] = h.BodyRoot
+	return merkleize(chunks)
+}
+
+// merkleize computes the root of a binary merkle tree. The number of chunks must be a power of two.
+func merkleize(chunks [][32]byte) common.Hash {
+	for len(chunks) > 1 {
+		next := make([][32]byte, len(chunks)/2)
+		for i := range next {
+			next[i] = hashPair(chunks[2*i], chunks[2*i+1])
+		}
+		chunks = next
+	}
+	return chunks[0]
+}
+
+func hashPair(a, b [32]byte) [32]byte {
+	h := sha256.New()
+	h.Write(a[:])
+	h.Write(b[:])
+	var out [32]byte
+	h.Sum(out[:0])
+	return out
+}
+
+// commitmentRoot computes the hash tree root of a 48-byte kzg commitment
+func commitmentRoot(commitment []byte) [32]byte {
+	var chunks [2][32]byte
+	copy(chunks[0][:], commitment[:32])
+	copy(chunks[1][:], commitment[32:])
+	return hashPair(chunks[0], chunks[1])
+}
+
+// isValidMerkleBranch implements is_valid_merkle_branch from the consensus specs
+func isValidMerkleBranch(leaf [32]byte, branch []common.Hash, depth int, index uint64, root common.Hash) bool {
+	if len(branch) != depth {
+		return false
+	}
+	value := leaf
+	for i := 0; i < depth; i++ {
+		if (index>>i)&1 == 1 {
+			value = hashPair(branch[i], value)
+		} else {
+			value = hashPair(value, branch[i])
+		}
+	}
+	return value == root
+}
+
+// VerifyInclusionProof checks that the sidecar's kzg commitment is included in the body of its block header
+func (s *BlobSidecar) VerifyInclusionProof() error {
+	if s.Index >= maxBlobCommitmentsPerBlock {
+		return fmt.Errorf("blob index %d out of range", s.Index)
+	}
+	// get_subtree_index(get_generalized_index(BeaconBlockBody, 'blob_kzg_commitments', index))
+	index := uint64(blobKzgCommitmentsIndex)<<blobCommitmentsListDepth | s.Index
+	leaf := commitmentRoot(s.KZGCommitment[:])
+	if !isValidMerkleBranch(leaf, s.KZGCommitmentInclusionProof, kzgCommitmentInclusionProofDepth, index, s.SignedBlockHeader.Message.BodyRoot) {
+		return errors.New("invalid kzg commitment inclusion proof")
+	}
+	return nil
+}
+
+// blobSidecarSSZSize is the size of an SSZ encoded BlobSidecar, which only has fixed size fields
+const blobSidecarSSZSize = 8 + len(kzg4844.Blob{}) + 48 + 48 + signedBeaconBlockHeaderSSZSize + kzgCommitmentInclusionProofDepth*32
+
+const signedBeaconBlockHeaderSSZSize = 8 + 8 + 32 + 32 + 32 + 96
+
+// UnmarshalSSZ decodes an SSZ encoded BlobSidecar
+func (s *BlobSidecar) UnmarshalSSZ(b []byte) error {
+	if len(b) != blobSidecarSSZSize {
+		return fmt.Errorf("invalid blob sidecar size %d", len(b))
+	}
+	s.Index = binary.LittleEndian.Uint64(b[0:8])
+	b = b[8:]
+	b = b[copy(s.Blob[:], b):]
+	b = b[copy(s.KZGCommitment[:], b):]
+	b = b[copy(s.KZGProof[:], b):]
+
+	s.SignedBlockHeader.unmarshalSSZ(b)
+	b = b[signedBeaconBlockHeaderSSZSize:]
+
+	s.KZGCommitmentInclusionProof = make([]common.Hash, kzgCommitmentInclusionProofDepth)
+	for i := range s.KZGCommitmentInclusionProof {
+		s.KZGCommitmentInclusionProof[i] = common.BytesToHash(b[i*32 : (i+1)*32])
+	}
+	return nil
+}
+
+func (h *SignedBeaconBlockHeader) unmarshalSSZ(b []byte) {
+	h.Message.Slot = binary.LittleEndian.Uint64(b[0:8])
+	h.Message.ProposerIndex = binary.LittleEndian.Uint64(b[8:16])
+	h.Message.ParentRoot = common.BytesToHash(b[16:48])
+	h.Message.StateRoot = common.BytesToHash(b[48:80])
+	h.Message.BodyRoot = common.BytesToHash(b[80:112])
+	h.Signature = common.CopyBytes(b[112:208])
+}
+
+// dataColumnSidecarFixedSize is the size of the fixed part of an SSZ encoded DataColumnSidecar, which is followed by
+// the column, kzg_commitments and kzg_proofs lists
+const dataColumnSidecarFixedSize = 8 + 3*4 + signedBeaconBlockHeaderSSZSize + kzgCommitmentsInclusionProofDepth*32
+
+// UnmarshalSSZ decodes an SSZ encoded DataColumnSidecar
+func (s *DataColumnSidecar) UnmarshalSSZ(b []byte) error {
+	if len(b) < dataColumnSidecarFixedSize {
+		return fmt.Errorf("invalid data column sidecar size %d", len(b))
+	}
+	s.Index = binary.LittleEndian.Uint64(b[0:8])
+	columnOffset := binary.LittleEndian.Uint32(b[8:12])
+	commitmentsOffset := binary.LittleEndian.Uint32(b[12:16])
+	proofsOffset := binary.LittleEndian.Uint32(b[16:20])
+	if columnOffset != dataColumnSidecarFixedSize || commitmentsOffset < columnOffset || proofsOffset < commitmentsOffset ||
+		int(proofsOffset) > len(b) {
+		return errors.New("invalid data column sidecar offsets")
+	}
+	column := b[columnOffset:commitmentsOffset]
+	commitments := b[commitmentsOffset:proofsOffset]
+	proofs := b[proofsOffset:]
+	n := len(column) / bytesPerCell
+	if len(column) != n*bytesPerCell || len(commitments) != n*48 || len(proofs) != n*48 {
+		return errors.New("mismatched number of cells, kzg_commitments and kzg_proofs")
+	}
+	s.Column = make([]Cell, n)
+	s.KZGCommitments = make([]kzg4844.Commitment, n)
+	s.KZGProofs = make([]kzg4844.Proof, n)
+	for i := 0; i < n; i++ {
+		copy(s.Column[i][:], column[i*bytesPerCell:])
+		copy(s.KZGCommitments[i][:], commitments[i*48:])
+		copy(s.KZGProofs[i][:], proofs[i*48:])
+	}
+
+	s.SignedBlockHeader.unmarshalSSZ(b[20:])
+	b = b[20+signedBeaconBlockHeaderSSZSize:]
+	s.KZGCommitmentsInclusionProof = make([]common.Hash, kzgCommitmentsInclusionProofDepth)
+	for i := range s.KZGCommitmentsInclusionProof {
+		s.KZGCommitmentsInclusionProof[i] = common.BytesToHash(b[i*32 : (i+1)*32])
+	}
+	return nil
+}
+
+// unmarshalDataColumnSidecars decodes an SSZ encoded DataColumnSidecar, or a list of them as served by the beacon node
+// API
+func unmarshalDataColumnSidecars(b []byte) ([]*DataColumnSidecar, error) {
+	// a single sidecar starts with its index and the offset of its column, which a list can't
+	if len(b) >= 12 && binary.LittleEndian.Uint32(b[8:12]) == dataColumnSidecarFixedSize {
+		sidecar := new(DataColumnSidecar)
+		if err := sidecar.UnmarshalSSZ(b); err != nil {
+			return nil, err
+		}
+		return []*DataColumnSidecar{sidecar}, nil
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	if len(b) < 4 {
+		return nil, errors.New("invalid data column sidecar list")
+	}
+	first := binary.LittleEndian.Uint32(b[0:4])
+	if first%4 != 0 || first == 0 || int(first) > len(b) {
+		return nil, errors.New("invalid data column sidecar list offsets")
+	}
+	offsets := make([]int, first/4+1)
+	for i := range offsets[:len(offsets)-1] {
+		offsets[i] = int(binary.LittleEndian.Uint32(b[i*4:]))
+		if offsets[i] > len(b) || (i > 0 && offsets[i] < offsets[i-1]) {
+			return nil, errors.New("invalid data column sidecar list offsets")
+		}
+	}
+	offsets[len(offsets)-1] = len(b)
+	sidecars := make([]*DataColumnSidecar, len(offsets)-1)
+	for i := range sidecars {
+		sidecars[i] = new(DataColumnSidecar)
+		if err := sidecars[i].UnmarshalSSZ(b[offsets[i]:offsets[i+1]]); err != nil {
+			return nil, fmt.Errorf("%w: data column sidecar %d", err, i)
+		}
+	}
+	return sidecars, nil
+}
+
+// VerifyInclusionProof checks that the sidecar's kzg commitments are the blob_kzg_commitments of the body of its block
+// header
+func (s *DataColumnSidecar) VerifyInclusionProof() error {
+	if len(s.KZGCommitments) > maxBlobCommitmentsPerBlock {
+		return fmt.Errorf("too many kzg commitments %d", len(s.KZGCommitments))
+	}
+	leaves := make([][32]byte, maxBlobCommitmentsPerBlock)
+	for i := range s.KZGCommitments {
+		leaves[i] = commitmentRoot(s.KZGCommitments[i][:])
+	}
+	var length [32]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(len(s.KZGCommitments)))
+	leaf := hashPair(merkleize(leaves), length)
+	if !isValidMerkleBranch(leaf, s.KZGCommitmentsInclusionProof, kzgCommitmentsInclusionProofDepth, blobKzgCommitmentsIndex, s.SignedBlockHeader.Message.BodyRoot) {
+		return errors.New("invalid kzg commitments inclusion proof")
+	}
+	return nil
+}
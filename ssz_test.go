@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// merkleBranch returns the root of the tree over leaves along with the branch for the leaf at index
+func merkleBranch(leaves [][32]byte, index int) (common.Hash, []common.Hash) {
+	var branch []common.Hash
+	for len(leaves) > 1 {
+		branch = append(branch, leaves[index^1])
+		next := make([][32]byte, len(leaves)/2)
+		for i := range next {
+			next[i] = hashPair(leaves[2*i], leaves[2*i+1])
+		}
+		leaves = next
+		index /= 2
+	}
+	return leaves[0], branch
+}
+
+func TestVerifyInclusionProof(t *testing.T) {
+	commitments := make([][48]byte, 3)
+	commitmentLeaves := make([][32]byte, maxBlobCommitmentsPerBlock)
+	for i := range commitments {
+		for j := range commitments[i] {
+			commitments[i][j] = byte(i*48 + j)
+		}
+		commitmentLeaves[i] = commitmentRoot(commitments[i][:])
+	}
+
+	for i := range commitments {
+		listRoot, listBranch := merkleBranch(commitmentLeaves, i)
+		var length [32]byte
+		binary.LittleEndian.PutUint64(length[:], uint64(len(commitments)))
+
+		bodyLeaves := make([][32]byte, 16)
+		for j := range bodyLeaves {
+			bodyLeaves[j][0] = byte(j + 1)
+		}
+		bodyLeaves[blobKzgCommitmentsIndex] = hashPair(listRoot, length)
+		bodyRoot, bodyBranch := merkleBranch(bodyLeaves, blobKzgCommitmentsIndex)
+
+		sidecar := &BlobSidecar{Index: uint64(i)}
+		copy(sidecar.KZGCommitment[:], commitments[i][:])
+		sidecar.SignedBlockHeader.Message.BodyRoot = bodyRoot
+		sidecar.KZGCommitmentInclusionProof = append(append(listBranch, length), bodyBranch...)
+		if err := sidecar.VerifyInclusionProof(); err != nil {
+			t.Fatalf("(%d) expected valid proof: %v", i, err)
+		}
+
+		sidecar.Index = uint64((i + 1) % len(commitments))
+		if err := sidecar.VerifyInclusionProof(); err == nil {
+			t.Fatalf("(%d) expected proof for wrong index to fail", i)
+		}
+	}
+}
+
+func marshalBlobSidecarSSZ(s *BlobSidecar) []byte {
+	b := binary.LittleEndian.AppendUint64(nil, s.Index)
+	b = append(append(append(b, s.Blob[:]...), s.KZGCommitment[:]...), s.KZGProof[:]...)
+	h := s.SignedBlockHeader.Message
+	b = binary.LittleEndian.AppendUint64(b, h.Slot)
+	b = binary.LittleEndian.AppendUint64(b, h.ProposerIndex)
+	b = append(append(append(b, h.ParentRoot[:]...), h.StateRoot[:]...), h.BodyRoot[:]...)
+	b = append(b, s.SignedBlockHeader.Signature...)
+	for _, p := range s.KZGCommitmentInclusionProof {
+		b = append(b, p[:]...)
+	}
+	return b
+}
+
+func TestBlobSidecarUnmarshalSSZ(t *testing.T) {
+	for _, sidecar := range testBlobSidecars(t, 12) {
+		sidecar.SignedBlockHeader.Message.ParentRoot = common.Hash{1}
+		sidecar.SignedBlockHeader.Message.StateRoot = common.Hash{2}
+		enc := marshalBlobSidecarSSZ(sidecar)
+		if len(enc) != blobSidecarSSZSize {
+			t.Fatalf("unexpected encoding size %d", len(enc))
+		}
+		decoded := new(BlobSidecar)
+		if err := decoded.UnmarshalSSZ(enc); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(decoded, sidecar) {
+			t.Fatalf("(%d) unexpected decoded sidecar", sidecar.Index)
+		}
+		if err := verifyBlobSidecars("12", []*BlobSidecar{decoded}); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, size := range []int{0, len(enc) - 1, len(enc) + 1} {
+			if err := new(BlobSidecar).UnmarshalSSZ(append(enc, 0)[:size]); err == nil {
+				t.Fatalf("expected invalid size %d to be rejected", size)
+			}
+		}
+	}
+}
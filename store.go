@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	blobStoreIndexFile = "index.jsonl"
+	blobStoreSlotsFile = "slots"
+	blobStoreBlobsDir  = "blobs"
+)
+
+// blobStore is an on-disk store of raw blobs keyed by versioned hash. Every stored blob is recorded in an append-only
+// index file, and a slots file records the ranges of slots that were completely downloaded so that downloads can be
+// resumed.
+type blobStore struct {
+	dir     string
+	index   *os.File
+	indexed map[common.Hash]bool
+}
+
+// blobStoreEntry is a line of the store's index file
+type blobStoreEntry struct {
+	VersionedHash common.Hash   `json:"versioned_hash"`
+	Slot          uint64        `json:"slot"`
+	Index         uint64        `json:"index"`
+	BlockRoot     common.Hash   `json:"block_root"`
+	KZGCommitment hexutil.Bytes `json:"kzg_commitment"`
+	KZGProof      hexutil.Bytes `json:"kzg_proof"`
+}
+
+func openBlobStore(dir string) (*blobStore, error) {
+	if err := os.MkdirAll(filepath.Join(dir, blobStoreBlobsDir), 0o755); err != nil {
+		return nil, fmt.Errorf("%w: unable to create blob store", err)
+	}
+	index, err := os.OpenFile(filepath.Join(dir, blobStoreIndexFile), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to open blob store index", err)
+	}
+	indexed, terminated, err := readBlobStoreIndex(index)
+	if err != nil {
+		index.Close()
+		return nil, err
+	}
+	if !terminated {
+		if _, err := index.Write([]byte{'\n'}); err != nil {
+			index.Close()
+			return nil, fmt.Errorf("%w: unable to repair blob store index", err)
+		}
+	}
+	return &blobStore{dir: dir, index: index, indexed: indexed}, nil
+}
+
+// openBlobStoreReadOnly opens an existing blob store for reading blobs. Nothing is created or repaired, so blobs can't
+// be stored.
+func openBlobStoreReadOnly(dir string) (*blobStore, error) {
+	index, err := os.Open(filepath.Join(dir, blobStoreIndexFile))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to open blob store index", err)
+	}
+	indexed, _, err := readBlobStoreIndex(index)
+	if err != nil {
+		index.Close()
+		return nil, err
+	}
+	return &blobStore{dir: dir, index: index, indexed: indexed}, nil
+}
+
+// readBlobStoreIndex returns the versioned hashes recorded in the index, and whether its last line is terminated
+func readBlobStoreIndex(index io.Reader) (map[common.Hash]bool, bool, error) {
+	indexed := make(map[common.Hash]bool)
+	var terminated = true
+	scanner := bufio.NewScanner(index)
+	for scanner.Scan() {
+		var entry blobStoreEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// a partially written line from an interrupted download; the blob will be stored again
+			terminated = false
+			continue
+		}
+		terminated = true
+		indexed[entry.VersionedHash] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, false, fmt.Errorf("%w: unable to read blob store index", err)
+	}
+	return indexed, terminated, nil
+}
+
+func (s *blobStore) Close() error {
+	return s.index.Close()
+}
+
+func (s *blobStore) blobPath(versionedHash common.Hash) string {
+	return filepath.Join(s.dir, blobStoreBlobsDir, versionedHash.Hex()+".blob")
+}
+
+// Put stores the blob of a verified sidecar and records it in the index. Blobs that are already indexed are skipped.
+func (s *blobStore) Put(sidecar *BlobSidecar) (bool, error) {
+	versionedHash := kZGToVersionedHash(sidecar.KZGCommitment)
+	if s.indexed[versionedHash] {
+		return false, nil
+	}
+	if err := writeFileAtomic(s.blobPath(versionedHash), sidecar.Blob[:]); err != nil {
+		return false, fmt.Errorf("%w: unable to write blob %v", err, versionedHash)
+	}
+	entry, err := json.Marshal(&blobStoreEntry{
+		VersionedHash: versionedHash,
+		Slot:          sidecar.Slot(),
+		Index:         sidecar.Index,
+		BlockRoot:     sidecar.BlockRoot(),
+		KZGCommitment: sidecar.KZGCommitment[:],
+		KZGProof:      sidecar.KZGProof[:],
+	})
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.index.Write(append(entry, '\n')); err != nil {
+		return false, fmt.Errorf("%w: unable to write blob store index", err)
+	}
+	s.indexed[versionedHash] = true
+	return true, nil
+}
+
+// Get reads a stored blob
+func (s *blobStore) Get(versionedHash common.Hash) ([]byte, error) {
+	if !s.indexed[versionedHash] {
+		return nil, fmt.Errorf("blob %v is not in the blob store", versionedHash)
+	}
+	blob, err := os.ReadFile(s.blobPath(versionedHash))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read blob %v", err, versionedHash)
+	}
+	return blob, nil
+}
+
+// slotRange is an inclusive range of slots
+type slotRange struct {
+	From, To uint64
+}
+
+// DownloadedSlots returns the sorted, non-adjacent ranges of slots that were completely downloaded into the store
+func (s *blobStore) DownloadedSlots() ([]slotRange, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, blobStoreSlotsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var ranges []slotRange
+	for _, line := range strings.Fields(string(data)) {
+		from, to, ok := strings.Cut(line, "-")
+		var r slotRange
+		if ok {
+			if r.From, err = strconv.ParseUint(from, 10, 64); err == nil {
+				r.To, err = strconv.ParseUint(to, 10, 64)
+			}
+		}
+		if !ok || err != nil || r.From > r.To {
+			return nil, fmt.Errorf("invalid slot range %q in blob store", line)
+		}
+		ranges = append(ranges, r)
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].From < ranges[j].From })
+	merged := ranges[:0]
+	for _, r := range ranges {
+		if n := len(merged); n != 0 && r.From <= merged[n-1].To+1 {
+			if r.To > merged[n-1].To {
+				merged[n-1].To = r.To
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged, nil
+}
+
+// SetDownloaded records that the slot was completely downloaded. The index is synced first so that the recorded slots
+// never get ahead of the stored blobs.
+func (s *blobStore) SetDownloaded(slot uint64) error {
+	ranges, err := s.DownloadedSlots()
+	if err != nil {
+		return err
+	}
+	if err := s.index.Sync(); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, r := range addSlot(ranges, slot) {
+		fmt.Fprintf(&b, "%d-%d\n", r.From, r.To)
+	}
+	return writeFileAtomic(filepath.Join(s.dir, blobStoreSlotsFile), []byte(b.String()))
+}
+
+// addSlot adds a slot to sorted ranges, merging it with adjacent ones
+func addSlot(ranges []slotRange, slot uint64) []slotRange {
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].To+1 >= slot })
+	switch {
+	case i < len(ranges) && ranges[i].From <= slot && slot <= ranges[i].To:
+		return ranges
+	case i < len(ranges) && ranges[i].To+1 == slot:
+		ranges[i].To = slot
+		if i+1 < len(ranges) && ranges[i+1].From == slot+1 {
+			ranges[i].To = ranges[i+1].To
+			ranges = append(ranges[:i+1], ranges[i+2:]...)
+		}
+		return ranges
+	case i < len(ranges) && ranges[i].From == slot+1:
+		ranges[i].From = slot
+		return ranges
+	}
+	ranges = append(ranges, slotRange{})
+	copy(ranges[i+1:], ranges[i:])
+	ranges[i] = slotRange{From: slot, To: slot}
+	return ranges
+}
+
+// containsSlot reports whether the slot is in one of the sorted ranges
+func containsSlot(ranges []slotRange, slot uint64) bool {
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].To >= slot })
+	return i < len(ranges) && ranges[i].From <= slot
+}
+
+func writeFileAtomic(name string, data []byte) error {
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
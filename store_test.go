@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBlobStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := openBlobStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sidecars := testBlobSidecars(t, 7)
+	for _, sidecar := range sidecars {
+		if ok, err := store.Put(sidecar); err != nil || !ok {
+			t.Fatalf("unable to store blob: %v", err)
+		}
+	}
+	if ok, err := store.Put(sidecars[0]); err != nil || ok {
+		t.Fatalf("expected stored blob to be skipped: %v", err)
+	}
+	if err := store.SetDownloaded(7); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	// a partially written line of an interrupted download
+	index, err := os.OpenFile(filepath.Join(dir, blobStoreIndexFile), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := index.Write([]byte(`{"versioned_hash":"0x01`)); err != nil {
+		t.Fatal(err)
+	}
+	index.Close()
+
+	store, err = openBlobStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sidecar := range sidecars {
+		blob, err := store.Get(kZGToVersionedHash(sidecar.KZGCommitment))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(blob, sidecar.Blob[:]) {
+			t.Fatal("unexpected blob")
+		}
+	}
+	if _, err := store.Get(common.Hash{1}); err == nil {
+		t.Fatal("expected unknown blob")
+	}
+	downloaded, err := store.DownloadedSlots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []slotRange{{7, 7}}; !reflect.DeepEqual(downloaded, expected) {
+		t.Fatalf("expected downloaded slots %v, got %v", expected, downloaded)
+	}
+
+	// entries written after the repair are readable
+	store.indexed = make(map[common.Hash]bool)
+	if ok, err := store.Put(sidecars[0]); err != nil || !ok {
+		t.Fatalf("unable to store blob: %v", err)
+	}
+	store.Close()
+	store, err = openBlobStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if len(store.indexed) != len(sidecars) {
+		t.Fatalf("expected %d indexed blobs, got %d", len(sidecars), len(store.indexed))
+	}
+}
+
+func TestDownloadedSlots(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected []slotRange
+		err      bool
+	}{
+		{file: "", expected: nil},
+		{file: "1-3\n", expected: []slotRange{{1, 3}}},
+		{file: "10-12\n1-3\n4-5\n", expected: []slotRange{{1, 5}, {10, 12}}},
+		{file: "1-10\n2-3\n", expected: []slotRange{{1, 10}}},
+		{file: "3-1\n", err: true},
+		{file: "1\n", err: true},
+		{file: "a-b\n", err: true},
+	}
+	for i, tt := range tests {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, blobStoreSlotsFile), []byte(tt.file), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		store := &blobStore{dir: dir}
+		ranges, err := store.DownloadedSlots()
+		if (err != nil) != tt.err {
+			t.Fatalf("(%d) unexpected error %v", i, err)
+		}
+		if !tt.err && !reflect.DeepEqual(ranges, tt.expected) {
+			t.Fatalf("(%d) expected %v, got %v", i, tt.expected, ranges)
+		}
+	}
+}
+
+func TestAddSlot(t *testing.T) {
+	tests := []struct {
+		ranges   []slotRange
+		slot     uint64
+		expected []slotRange
+	}{
+		{nil, 5, []slotRange{{5, 5}}},
+		{[]slotRange{{5, 5}}, 5, []slotRange{{5, 5}}},
+		{[]slotRange{{5, 5}}, 6, []slotRange{{5, 6}}},
+		{[]slotRange{{5, 5}}, 4, []slotRange{{4, 5}}},
+		{[]slotRange{{5, 5}}, 7, []slotRange{{5, 5}, {7, 7}}},
+		{[]slotRange{{5, 5}}, 3, []slotRange{{3, 3}, {5, 5}}},
+		{[]slotRange{{1, 3}, {5, 8}}, 4, []slotRange{{1, 8}}},
+		{[]slotRange{{1, 3}, {10, 12}}, 6, []slotRange{{1, 3}, {6, 6}, {10, 12}}},
+		{[]slotRange{{0, 3}}, 0, []slotRange{{0, 3}}},
+	}
+	for i, tt := range tests {
+		ranges := addSlot(append([]slotRange{}, tt.ranges...), tt.slot)
+		if !reflect.DeepEqual(ranges, tt.expected) {
+			t.Fatalf("(%d) expected %v, got %v", i, tt.expected, ranges)
+		}
+		for _, r := range tt.expected {
+			if !containsSlot(ranges, r.From) || !containsSlot(ranges, r.To) {
+				t.Fatalf("(%d) expected %v to contain %v", i, ranges, r)
+			}
+		}
+		if containsSlot(ranges, 100) {
+			t.Fatalf("(%d) unexpected slot 100 in %v", i, ranges)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// taikoMaxTxListBytes bounds the size of a decompressed transaction list
+const taikoMaxTxListBytes = 16 << 20
+
+// taikoBlob holds the transaction lists of the Taiko blocks or batches proposed with a blob
+type taikoBlob struct {
+	TxLists [][]hexutil.Bytes `json:"tx_lists"`
+}
+
+// taikoDecoder prints the transaction lists of Taiko blobs. Each transaction list is a zlib compressed RLP list of
+// transactions, and a blob may hold several of them back to back.
+type taikoDecoder struct {
+	out *json.Encoder
+}
+
+func newTaikoDecoder(out io.Writer) *taikoDecoder {
+	return &taikoDecoder{out: json.NewEncoder(out)}
+}
+
+func (d *taikoDecoder) Codec() BlobCodec {
+	return opCodec{}
+}
+
+func (d *taikoDecoder) AddBlob(data []byte) error {
+	blob := new(taikoBlob)
+	r := bytes.NewReader(data)
+	for !isZero(data[len(data)-r.Len():]) {
+		zr, err := zlib.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("%w: invalid tx list %d", err, len(blob.TxLists))
+		}
+		var txs []rlp.RawValue
+		if err := rlp.NewStream(io.LimitReader(zr, taikoMaxTxListBytes), 0).Decode(&txs); err != nil {
+			return fmt.Errorf("%w: invalid tx list %d", err, len(blob.TxLists))
+		}
+		// drain the stream so the reader is positioned after its checksum
+		if _, err := io.Copy(io.Discard, zr); err != nil {
+			return fmt.Errorf("%w: invalid tx list %d", err, len(blob.TxLists))
+		}
+		list := make([]hexutil.Bytes, 0, len(txs))
+		for _, tx := range txs {
+			list = append(list, canonicalTxBytes(tx))
+		}
+		blob.TxLists = append(blob.TxLists, list)
+	}
+	return d.out.Encode(blob)
+}
+
+func (d *taikoDecoder) Flush() error {
+	return nil
+}
+
+// canonicalTxBytes converts a transaction in an RLP list to its binary encoding. Typed transactions are wrapped in an
+// RLP string inside lists.
+func canonicalTxBytes(tx rlp.RawValue) []byte {
+	kind, content, _, err := rlp.Split(tx)
+	if err == nil && kind == rlp.String {
+		return content
+	}
+	return tx
+}
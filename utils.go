@@ -2,10 +2,12 @@ package main
 
 import (
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
 
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/params"
@@ -32,29 +34,132 @@ func encodeBlobs(data []byte) []kzg4844.Blob {
 	return blobs
 }
 
-func EncodeBlobs(data []byte) ([]kzg4844.Blob, []kzg4844.Commitment, []kzg4844.Proof, []common.Hash, error) {
+func EncodeBlobs(data []byte, codec BlobCodec) ([]kzg4844.Blob, []kzg4844.Commitment, []kzg4844.Proof, []common.Hash, error) {
+	blobs, err := codec.Encode(data)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	commits, proofs, versionedHashes, err := CommitBlobs(blobs)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return blobs, commits, proofs, versionedHashes, nil
+}
+
+// CommitBlobs validates the blobs and computes their commitments, proofs and versioned hashes
+func CommitBlobs(blobs []kzg4844.Blob) ([]kzg4844.Commitment, []kzg4844.Proof, []common.Hash, error) {
+	commits, versionedHashes, err := BlobCommitments(blobs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var proofs []kzg4844.Proof
+	for i, blob := range blobs {
+		proof, err := kzg4844.ComputeBlobProof(blob, commits[i])
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		proofs = append(proofs, proof)
+	}
+	return commits, proofs, versionedHashes, nil
+}
+
+// BlobCommitments validates the blobs and computes their commitments and versioned hashes, without the blob proofs
+func BlobCommitments(blobs []kzg4844.Blob) ([]kzg4844.Commitment, []common.Hash, error) {
+	if err := validateBlobs(blobs); err != nil {
+		return nil, nil, err
+	}
 	var (
-		blobs           = encodeBlobs(data)
 		commits         []kzg4844.Commitment
-		proofs          []kzg4844.Proof
 		versionedHashes []common.Hash
 	)
 	for _, blob := range blobs {
 		commit, err := kzg4844.BlobToCommitment(blob)
 		if err != nil {
-			return nil, nil, nil, nil, err
+			return nil, nil, err
 		}
 		commits = append(commits, commit)
+		versionedHashes = append(versionedHashes, kZGToVersionedHash(commit))
+	}
+	return commits, versionedHashes, nil
+}
 
-		proof, err := kzg4844.ComputeBlobProof(blob, commit)
+// ComputeBlobCells validates the blobs and computes their commitments, and the cells of their extension along with the
+// cell proofs
+func ComputeBlobCells(blobs []kzg4844.Blob) ([]kzg4844.Commitment, [][]Cell, [][]kzg4844.Proof, error) {
+	if err := validateBlobs(blobs); err != nil {
+		return nil, nil, nil, err
+	}
+	var (
+		commits []kzg4844.Commitment
+		cells   [][]Cell
+		proofs  [][]kzg4844.Proof
+	)
+	for i, blob := range blobs {
+		commit, err := kzg4844.BlobToCommitment(blob)
 		if err != nil {
-			return nil, nil, nil, nil, err
+			return nil, nil, nil, err
 		}
-		proofs = append(proofs, proof)
+		commits = append(commits, commit)
 
-		versionedHashes = append(versionedHashes, kZGToVersionedHash(commit))
+		blobCells, cellProofs, err := ComputeCellsAndKZGProofs(blob)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: unable to compute cells of blob %d", err, i)
+		}
+		cells = append(cells, blobCells)
+		proofs = append(proofs, cellProofs)
 	}
-	return blobs, commits, proofs, versionedHashes, nil
+	return commits, cells, proofs, nil
+}
+
+// EvaluateBlob evaluates the polynomial of the blob at z with the barycentric formula, without computing a proof. The
+// blob holds the evaluations of the polynomial over the bit-reversed roots of unity.
+func EvaluateBlob(blob kzg4844.Blob, z kzg4844.Point) (kzg4844.Claim, error) {
+	if err := validateBlobs([]kzg4844.Blob{blob}); err != nil {
+		return kzg4844.Claim{}, err
+	}
+	var x fr.Element
+	if err := x.SetBytesCanonical(z[:]); err != nil {
+		return kzg4844.Claim{}, fmt.Errorf("%w: invalid evaluation point", err)
+	}
+	values := make([]fr.Element, params.BlobTxFieldElementsPerBlob)
+	for i := range values {
+		values[i].SetBytes(blob[i*32 : (i+1)*32])
+	}
+	y := evaluateBarycentric(values, blobDomain(), x)
+	return y.Bytes(), nil
+}
+
+const blobSize = params.BlobTxFieldElementsPerBlob * 32
+
+var errNonCanonicalFieldElement = errors.New("field element is not below the BLS modulus")
+
+// validateBlobs checks that every field element of the blobs is canonical, i.e. below the BLS12-381 scalar modulus
+func validateBlobs(blobs []kzg4844.Blob) error {
+	for i := range blobs {
+		for j := 0; j < params.BlobTxFieldElementsPerBlob; j++ {
+			if !isCanonicalFieldElement(blobs[i][j*32 : (j+1)*32]) {
+				return fmt.Errorf("%w: blob %d field element %d", errNonCanonicalFieldElement, i, j)
+			}
+		}
+	}
+	return nil
+}
+
+func isCanonicalFieldElement(b []byte) bool {
+	var e fr.Element
+	return e.SetBytesCanonical(b) == nil
+}
+
+// splitRawBlobs splits already encoded blobs, which must be a multiple of the blob size
+func splitRawBlobs(data []byte) ([]kzg4844.Blob, error) {
+	if len(data) == 0 || len(data)%blobSize != 0 {
+		return nil, fmt.Errorf("raw blob data of %d bytes is not a multiple of %d", len(data), blobSize)
+	}
+	blobs := make([]kzg4844.Blob, len(data)/blobSize)
+	for i := range blobs {
+		copy(blobs[i][:], data[i*blobSize:])
+	}
+	return blobs, nil
 }
 
 var blobCommitmentVersionKZG uint8 = 0x01
@@ -73,7 +178,8 @@ func DecodeBlob(blob []byte) []byte {
 	}
 	var data []byte
 
-	// XXX: the following removes trailing 0s in each field element (see EncodeBlobs), which could be unexpected for certain blobs
+	// XXX: the following removes trailing 0s in each field element (see EncodeBlobs), which could be unexpected for certain blobs.
+	// Use the lossless codec for data that may end in zeros.
 	j := 0
 	for i := 0; i < params.BlobTxFieldElementsPerBlob; i++ {
 		data = append(data, blob[j:j+31]...)
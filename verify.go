@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/urfave/cli"
+)
+
+func VerifyApp(cliCtx *cli.Context) error {
+	if cliCtx.IsSet(VerifyPointEvalInputFlag.Name) {
+		input, err := hexutil.Decode(cliCtx.String(VerifyPointEvalInputFlag.Name))
+		if err != nil {
+			return fmt.Errorf("%w: invalid point evaluation input", err)
+		}
+		in, err := decodePointEvaluationInput(input)
+		if err != nil {
+			return err
+		}
+		log.Printf(
+			"\nversionedHash %x \n"+"z %x \n"+"y %x \n"+"commitment %x \n"+"proof %x",
+			in.VersionedHash[:], in.Z[:], in.Y[:], in.Commitment[:], in.Proof[:])
+		out, err := verifyPointEvaluation(input)
+		if err != nil {
+			return err
+		}
+		log.Printf("point evaluation verified. fieldElementsPerBlob=%x blsModulus=%x", out[:32], out[32:])
+		return nil
+	}
+
+	var (
+		blob []byte
+		err  error
+	)
+	switch {
+	case cliCtx.IsSet(VerifyBlobFileFlag.Name):
+		blob, err = readBlobFile(cliCtx.String(VerifyBlobFileFlag.Name))
+	case cliCtx.IsSet(VerifyBlobFlag.Name):
+		blob, err = hexutil.Decode(cliCtx.String(VerifyBlobFlag.Name))
+		if err == nil && len(blob) != blobSize {
+			err = fmt.Errorf("invalid blob length %d", len(blob))
+		}
+	default:
+		return fmt.Errorf("either --%s or --%s is required", VerifyBlobFileFlag.Name, VerifyBlobFlag.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	var (
+		commitment    *kzg4844.Commitment
+		proof         *kzg4844.Proof
+		versionedHash *common.Hash
+		checked       []string
+	)
+	if cliCtx.IsSet(VerifyCommitmentFlag.Name) {
+		commitment = new(kzg4844.Commitment)
+		if err := decodeFixedHex(cliCtx.String(VerifyCommitmentFlag.Name), commitment[:]); err != nil {
+			return fmt.Errorf("%w: invalid commitment", err)
+		}
+		checked = append(checked, VerifyCommitmentFlag.Name)
+	}
+	if cliCtx.IsSet(VerifyProofFlag.Name) {
+		proof = new(kzg4844.Proof)
+		if err := decodeFixedHex(cliCtx.String(VerifyProofFlag.Name), proof[:]); err != nil {
+			return fmt.Errorf("%w: invalid proof", err)
+		}
+		checked = append(checked, VerifyProofFlag.Name)
+	}
+	if cliCtx.IsSet(VerifyVersionedHashFlag.Name) {
+		versionedHash = new(common.Hash)
+		if err := decodeFixedHex(cliCtx.String(VerifyVersionedHashFlag.Name), versionedHash[:]); err != nil {
+			return fmt.Errorf("%w: invalid versioned hash", err)
+		}
+		checked = append(checked, VerifyVersionedHashFlag.Name)
+	}
+	if len(checked) == 0 {
+		return fmt.Errorf("at least one of --%s, --%s or --%s is required", VerifyCommitmentFlag.Name, VerifyProofFlag.Name,
+			VerifyVersionedHashFlag.Name)
+	}
+
+	if err := verifyBlob(kzg4844.Blob(blob), commitment, proof, versionedHash); err != nil {
+		return err
+	}
+	log.Printf("blob verified. checked=%s", strings.Join(checked, ","))
+	return nil
+}
+
+// verifyBlob checks the commitment, blob proof and versioned hash of a blob. Those that are nil are skipped, except
+// the commitment, which is computed from the blob if needed.
+func verifyBlob(blob kzg4844.Blob, commitment *kzg4844.Commitment, proof *kzg4844.Proof, versionedHash *common.Hash) error {
+	if err := validateBlobs([]kzg4844.Blob{blob}); err != nil {
+		return err
+	}
+	computed, err := kzg4844.BlobToCommitment(blob)
+	if err != nil {
+		return fmt.Errorf("%w: unable to compute commitment", err)
+	}
+	if commitment == nil {
+		commitment = &computed
+	} else if *commitment != computed {
+		return fmt.Errorf("commitment mismatch. expected %x, got %x", computed[:], commitment[:])
+	}
+	if proof != nil {
+		if err := kzg4844.VerifyBlobProof(blob, *commitment, *proof); err != nil {
+			return fmt.Errorf("%w: invalid blob proof", err)
+		}
+	}
+	if versionedHash != nil {
+		if expected := kZGToVersionedHash(*commitment); *versionedHash != expected {
+			return fmt.Errorf("versioned hash mismatch. expected %v, got %v", expected, *versionedHash)
+		}
+	}
+	return nil
+}
+
+// decodeFixedHex decodes a hex string into b, which it must fill exactly
+func decodeFixedHex(s string, b []byte) error {
+	data, err := hexutil.Decode(s)
+	if err != nil {
+		return err
+	}
+	if len(data) != len(b) {
+		return errors.New("invalid length")
+	}
+	copy(b, data)
+	return nil
+}
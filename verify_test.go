@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestVerifyBlob(t *testing.T) {
+	blobs, commitments, proofs, versionedHashes, err := EncodeBlobs([]byte("hello blobs"), losslessCodec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyBlob(blobs[0], &commitments[0], &proofs[0], &versionedHashes[0]); err != nil {
+		t.Fatal(err)
+	}
+	// the commitment is computed when only the versioned hash is given
+	if err := verifyBlob(blobs[0], nil, nil, &versionedHashes[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	other, otherCommitments, otherProofs, _, err := EncodeBlobs([]byte("other blobs"), losslessCodec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrongHash := common.Hash{0x01}
+	for name, err := range map[string]error{
+		"commitment":     verifyBlob(blobs[0], &otherCommitments[0], nil, nil),
+		"proof":          verifyBlob(blobs[0], &commitments[0], &otherProofs[0], nil),
+		"versioned hash": verifyBlob(blobs[0], nil, nil, &wrongHash),
+		"blob":           verifyBlob(other[0], &commitments[0], &proofs[0], &versionedHashes[0]),
+	} {
+		if err == nil {
+			t.Fatalf("expected invalid %s to fail", name)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// zksyncCodec is the blob encoding of zkSync Era pubdata. The pubdata is split into 31 byte chunks that are the
+// coefficients of a polynomial, and the blob holds the evaluations of that polynomial.
+type zksyncCodec struct{}
+
+func (zksyncCodec) Encode(data []byte) ([]kzg4844.Blob, error) {
+	return chunkBlobs(data, paddedBlobCapacity, func(chunk []byte) kzg4844.Blob {
+		coefficients := make([]fr.Element, params.BlobTxFieldElementsPerBlob)
+		for i := range coefficients {
+			var coefficient [32]byte
+			chunk = chunk[copy(coefficient[1:], chunk):]
+			coefficients[i].SetBytes(coefficient[:])
+		}
+		return coefficientsToBlob(coefficients)
+	}), nil
+}
+
+func (zksyncCodec) Decode(blob []byte) ([]byte, error) {
+	coefficients, err := blobCoefficients(blob)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 0, paddedBlobCapacity)
+	for i := range coefficients {
+		b := coefficients[i].Bytes()
+		if b[0] != 0 {
+			return nil, fmt.Errorf("coefficient %d exceeds 31 bytes", i)
+		}
+		data = append(data, b[1:]...)
+	}
+	return data, nil
+}
+
+const (
+	zksyncL2ToL1LogSize           = 88
+	zksyncStateDiffCompressionV1  = 1
+	zksyncStateDiffOperationShift = 3
+	zksyncStateDiffOperationMask  = 0b111
+)
+
+type zksyncL2ToL1Log struct {
+	ShardID         uint8          `json:"shard_id"`
+	IsService       bool           `json:"is_service"`
+	TxNumberInBlock uint16         `json:"tx_number_in_block"`
+	Sender          common.Address `json:"sender"`
+	Key             common.Hash    `json:"key"`
+	Value           common.Hash    `json:"value"`
+}
+
+type zksyncStateDiff struct {
+	DerivedKey       *common.Hash  `json:"derived_key,omitempty"`
+	EnumerationIndex uint64        `json:"enumeration_index,omitempty"`
+	Operation        string        `json:"operation"`
+	Value            hexutil.Bytes `json:"value"`
+}
+
+// zksyncPubdata is the pubdata of a zkSync Era batch
+type zksyncPubdata struct {
+	L2ToL1Logs           []zksyncL2ToL1Log `json:"l2_to_l1_logs"`
+	Messages             []hexutil.Bytes   `json:"messages"`
+	Bytecodes            []hexutil.Bytes   `json:"bytecodes"`
+	EnumerationIndexSize uint8             `json:"enumeration_index_size"`
+	InitialWrites        []zksyncStateDiff `json:"initial_writes"`
+	RepeatedWrites       []zksyncStateDiff `json:"repeated_writes"`
+}
+
+// zksyncDecoder prints the pubdata of zkSync Era batches, which spans every blob of the commit transaction
+type zksyncDecoder struct {
+	out   *json.Encoder
+	buf   []byte
+	blobs int
+}
+
+func newZKSyncDecoder(out io.Writer) *zksyncDecoder {
+	return &zksyncDecoder{out: json.NewEncoder(out)}
+}
+
+func (d *zksyncDecoder) Codec() BlobCodec {
+	return zksyncCodec{}
+}
+
+func (d *zksyncDecoder) AddBlob(data []byte) error {
+	d.buf = append(d.buf, data...)
+	d.blobs++
+	pubdata, err := decodeZKSyncPubdata(d.buf)
+	if errors.Is(err, io.ErrUnexpectedEOF) && d.blobs < maxBlobsPerBlock {
+		// the pubdata continues in the next blob
+		return nil
+	}
+	d.buf, d.blobs = nil, 0
+	if err != nil {
+		return err
+	}
+	return d.out.Encode(pubdata)
+}
+
+func (d *zksyncDecoder) Flush() error {
+	if d.blobs != 0 {
+		log.Printf("incomplete zksync pubdata. blobs=%d", d.blobs)
+	}
+	return nil
+}
+
+// decodeZKSyncPubdata decodes the logs, messages, bytecodes and compressed state diffs of a batch. Trailing zeros
+// padding the last blob are ignored. io.ErrUnexpectedEOF is returned if the data is truncated.
+func decodeZKSyncPubdata(data []byte) (*zksyncPubdata, error) {
+	r := &byteReader{data: data}
+	p := new(zksyncPubdata)
+
+	n := r.uint32()
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		b := r.bytes(zksyncL2ToL1LogSize)
+		if r.err != nil {
+			break
+		}
+		p.L2ToL1Logs = append(p.L2ToL1Logs, zksyncL2ToL1Log{
+			ShardID:         b[0],
+			IsService:       b[1] != 0,
+			TxNumberInBlock: binary.BigEndian.Uint16(b[2:4]),
+			Sender:          common.BytesToAddress(b[4:24]),
+			Key:             common.BytesToHash(b[24:56]),
+			Value:           common.BytesToHash(b[56:88]),
+		})
+	}
+	n = r.uint32()
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		p.Messages = append(p.Messages, r.bytes(int(r.uint32())))
+	}
+	n = r.uint32()
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		p.Bytecodes = append(p.Bytecodes, r.bytes(int(r.uint32())))
+	}
+
+	if version := r.uint8(); r.err == nil && version != zksyncStateDiffCompressionV1 {
+		return nil, fmt.Errorf("unsupported state diff compression version %d", version)
+	}
+	length := r.uint24()
+	p.EnumerationIndexSize = r.uint8()
+	diffs := &byteReader{data: r.bytes(int(length))}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if p.EnumerationIndexSize == 0 || p.EnumerationIndexSize > 8 {
+		return nil, fmt.Errorf("invalid enumeration index size %d", p.EnumerationIndexSize)
+	}
+	if !isZero(data[r.offset:]) {
+		return nil, fmt.Errorf("unexpected data after %d bytes of pubdata", r.offset)
+	}
+
+	initialWrites := int(diffs.uint16())
+	for i := 0; diffs.err == nil && diffs.offset < len(diffs.data); i++ {
+		var diff zksyncStateDiff
+		if i < initialWrites {
+			key := common.BytesToHash(diffs.bytes(32))
+			diff.DerivedKey = &key
+		} else {
+			var index [8]byte
+			copy(index[8-p.EnumerationIndexSize:], diffs.bytes(int(p.EnumerationIndexSize)))
+			diff.EnumerationIndex = binary.BigEndian.Uint64(index[:])
+		}
+		metadata := diffs.uint8()
+		operation := metadata & zksyncStateDiffOperationMask
+		size := int(metadata >> zksyncStateDiffOperationShift)
+		switch operation {
+		case 0:
+			diff.Operation, size = "nothing", 32
+		case 1:
+			diff.Operation = "add"
+		case 2:
+			diff.Operation = "sub"
+		case 3:
+			diff.Operation = "transform"
+		default:
+			return nil, fmt.Errorf("invalid state diff operation %d", operation)
+		}
+		diff.Value = diffs.bytes(size)
+		if i < initialWrites {
+			p.InitialWrites = append(p.InitialWrites, diff)
+		} else {
+			p.RepeatedWrites = append(p.RepeatedWrites, diff)
+		}
+	}
+	if diffs.err != nil {
+		return nil, fmt.Errorf("%w: invalid state diffs", diffs.err)
+	}
+	if len(p.InitialWrites) != initialWrites {
+		return nil, fmt.Errorf("expected %d initial writes, got %d", initialWrites, len(p.InitialWrites))
+	}
+	return p, nil
+}